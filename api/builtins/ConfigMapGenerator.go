@@ -31,7 +31,8 @@ func (p *ConfigMapGeneratorPlugin) Config(h *resmap.PluginHelpers, config []byte
 
 func (p *ConfigMapGeneratorPlugin) Generate() (resmap.ResMap, error) {
 	return p.h.ResmapFactory().FromConfigMapArgs(
-		kv.NewLoader(p.h.Loader(), p.h.Validator()), p.ConfigMapArgs)
+		kv.NewLoaderWithExternalSources(p.h.Loader(), p.h.Validator(),
+			p.h.GeneralConfig().ExternalSourcesConfig), p.ConfigMapArgs)
 }
 
 func NewConfigMapGeneratorPlugin() resmap.GeneratorPlugin {
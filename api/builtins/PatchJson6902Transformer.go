@@ -16,11 +16,13 @@ import (
 )
 
 type PatchJson6902TransformerPlugin struct {
-	ldr          ifc.Loader
-	decodedPatch jsonpatch.Patch
-	Target       *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
-	Path         string          `json:"path,omitempty" yaml:"path,omitempty"`
-	JsonOp       string          `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+	ldr           ifc.Loader
+	decodedPatch  jsonpatch.Patch
+	failIfNoMatch bool
+	Target        *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+	Path          string          `json:"path,omitempty" yaml:"path,omitempty"`
+	JsonOp        string          `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+	Options       map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 func (p *PatchJson6902TransformerPlugin) Config(
@@ -30,6 +32,7 @@ func (p *PatchJson6902TransformerPlugin) Config(
 	if err != nil {
 		return err
 	}
+	p.failIfNoMatch = types.FailIfNoMatch(p.Options, h.GeneralConfig())
 	if p.Target.Name == "" {
 		return fmt.Errorf("must specify the target name")
 	}
@@ -77,6 +80,10 @@ func (p *PatchJson6902TransformerPlugin) Transform(m resmap.ResMap) error {
 	if err != nil {
 		return err
 	}
+	if len(resources) == 0 && p.failIfNoMatch {
+		return fmt.Errorf(
+			"patch matches no resources; target: %s", p.Target)
+	}
 	for _, res := range resources {
 		err = res.ApplyFilter(patchjson6902.Filter{
 			Patch: p.JsonOp,
@@ -16,6 +16,7 @@ type PatchStrategicMergeTransformerPlugin struct {
 	loadedPatches []*resource.Resource
 	Paths         []types.PatchStrategicMerge `json:"paths,omitempty" yaml:"paths,omitempty"`
 	Patches       string                      `json:"patches,omitempty" yaml:"patches,omitempty"`
+	Options       map[string]bool             `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 func (p *PatchStrategicMergeTransformerPlugin) Config(
@@ -70,8 +71,15 @@ func loadFromPaths(
 	return
 }
 
+// Transform applies each patch to the resource it names.  A patch
+// whose resource is missing is an error, as if failIfNoMatch were
+// always set, unless the patches are optional.
 func (p *PatchStrategicMergeTransformerPlugin) Transform(m resmap.ResMap) error {
 	for _, patch := range p.loadedPatches {
+		if p.Options[types.PatchOptionOptional] &&
+			len(m.GetMatchingResourcesByAnyId(patch.OrgId().Equals)) == 0 {
+			continue
+		}
 		target, err := m.GetById(patch.OrgId())
 		if err != nil {
 			return err
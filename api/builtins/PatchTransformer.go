@@ -9,19 +9,24 @@ import (
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"sigs.k8s.io/kustomize/api/filters/patchjson6902"
+	"sigs.k8s.io/kustomize/api/filters/patchjsonmerge"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/yaml"
 )
 
 type PatchTransformerPlugin struct {
-	loadedPatch  *resource.Resource
-	decodedPatch jsonpatch.Patch
-	Path         string          `json:"path,omitempty" yaml:"path,omitempty"`
-	Patch        string          `json:"patch,omitempty" yaml:"patch,omitempty"`
-	Target       *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
-	Options      map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
+	loadedPatch   *resource.Resource
+	decodedPatch  jsonpatch.Patch
+	mergePatch    *kyaml.RNode
+	failIfNoMatch bool
+	Path          string          `json:"path,omitempty" yaml:"path,omitempty"`
+	Patch         string          `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Target        *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+	Type          string          `json:"type,omitempty" yaml:"type,omitempty"`
+	Options       map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 func (p *PatchTransformerPlugin) Config(
@@ -30,6 +35,7 @@ func (p *PatchTransformerPlugin) Config(
 	if err != nil {
 		return err
 	}
+	p.failIfNoMatch = types.FailIfNoMatch(p.Options, h.GeneralConfig())
 	p.Patch = strings.TrimSpace(p.Patch)
 	if p.Patch == "" && p.Path == "" {
 		return fmt.Errorf(
@@ -47,6 +53,45 @@ func (p *PatchTransformerPlugin) Config(
 		p.Patch = string(loaded)
 	}
 
+	switch p.Type {
+	case "":
+		return p.inferPatch(h)
+	case types.PatchTypeStrategicMerge:
+		patchSM, err := h.ResmapFactory().RF().FromBytes([]byte(p.Patch))
+		if err != nil {
+			return fmt.Errorf(
+				"unable to parse SM patch from [%v]: %v", p.Patch, err)
+		}
+		p.setStrategicMergePatch(patchSM)
+	case types.PatchTypeJson6902:
+		patchJson, err := jsonPatchFromBytes([]byte(p.Patch))
+		if err != nil {
+			return fmt.Errorf(
+				"unable to parse JSON patch from [%v]: %v", p.Patch, err)
+		}
+		p.decodedPatch = patchJson
+	case types.PatchTypeMerge:
+		if p.Target == nil {
+			return fmt.Errorf("must specify a target for patch %s", p.Patch)
+		}
+		patchMerge, err := kyaml.Parse(p.Patch)
+		if err != nil || patchMerge.YNode().Kind != kyaml.MappingNode {
+			return fmt.Errorf(
+				"unable to parse merge patch object from [%v]", p.Patch)
+		}
+		p.mergePatch = patchMerge
+	default:
+		return fmt.Errorf(
+			"unknown patch type %q; must be one of %s, %s or %s", p.Type,
+			types.PatchTypeStrategicMerge, types.PatchTypeJson6902,
+			types.PatchTypeMerge)
+	}
+	return nil
+}
+
+// inferPatch loads the patch as either a strategic
+// merge patch or a JSON patch, depending on its content.
+func (p *PatchTransformerPlugin) inferPatch(h *resmap.PluginHelpers) error {
 	patchSM, errSM := h.ResmapFactory().RF().FromBytes([]byte(p.Patch))
 	patchJson, errJson := jsonPatchFromBytes([]byte(p.Patch))
 	if (errSM == nil && errJson == nil) ||
@@ -60,20 +105,27 @@ func (p *PatchTransformerPlugin) Config(
 			"unable to parse SM or JSON patch from [%v]", p.Patch)
 	}
 	if errSM == nil {
-		p.loadedPatch = patchSM
-		if p.Options["allowNameChange"] {
-			p.loadedPatch.AllowNameChange()
-		}
-		if p.Options["allowKindChange"] {
-			p.loadedPatch.AllowKindChange()
-		}
+		p.setStrategicMergePatch(patchSM)
 	} else {
 		p.decodedPatch = patchJson
 	}
 	return nil
 }
 
+func (p *PatchTransformerPlugin) setStrategicMergePatch(patch *resource.Resource) {
+	p.loadedPatch = patch
+	if p.Options["allowNameChange"] {
+		p.loadedPatch.AllowNameChange()
+	}
+	if p.Options["allowKindChange"] {
+		p.loadedPatch.AllowKindChange()
+	}
+}
+
 func (p *PatchTransformerPlugin) Transform(m resmap.ResMap) error {
+	if p.mergePatch != nil {
+		return p.transformMerge(m, p.mergePatch)
+	}
 	if p.loadedPatch == nil {
 		return p.transformJson6902(m, p.decodedPatch)
 	}
@@ -86,13 +138,17 @@ func (p *PatchTransformerPlugin) Transform(m resmap.ResMap) error {
 // the identifier of the patch.
 func (p *PatchTransformerPlugin) transformStrategicMerge(m resmap.ResMap, patch *resource.Resource) error {
 	if p.Target == nil {
+		if p.Options[types.PatchOptionOptional] &&
+			len(m.GetMatchingResourcesByAnyId(patch.OrgId().Equals)) == 0 {
+			return nil
+		}
 		target, err := m.GetById(patch.OrgId())
 		if err != nil {
 			return err
 		}
 		return target.ApplySmPatch(patch)
 	}
-	selected, err := m.Select(*p.Target)
+	selected, err := p.selectTargets(m)
 	if err != nil {
 		return err
 	}
@@ -105,7 +161,7 @@ func (p *PatchTransformerPlugin) transformJson6902(m resmap.ResMap, patch jsonpa
 	if p.Target == nil {
 		return fmt.Errorf("must specify a target for patch %s", p.Patch)
 	}
-	resources, err := m.Select(*p.Target)
+	resources, err := p.selectTargets(m)
 	if err != nil {
 		return err
 	}
@@ -121,6 +177,39 @@ func (p *PatchTransformerPlugin) transformJson6902(m resmap.ResMap, patch jsonpa
 	return nil
 }
 
+// transformMerge applies the provided JSON merge patch
+// to all the resources in the ResMap that match the Target.
+func (p *PatchTransformerPlugin) transformMerge(m resmap.ResMap, patch *kyaml.RNode) error {
+	resources, err := p.selectTargets(m)
+	if err != nil {
+		return err
+	}
+	for _, res := range resources {
+		res.StorePreviousId()
+		err = res.ApplyFilter(patchjsonmerge.Filter{
+			Patch: patch,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectTargets returns the resources matching the Target,
+// failing if there are none and the patch mustn't be a no-op.
+func (p *PatchTransformerPlugin) selectTargets(m resmap.ResMap) ([]*resource.Resource, error) {
+	resources, err := m.Select(*p.Target)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 && p.failIfNoMatch {
+		return nil, fmt.Errorf(
+			"patch matches no resources; target: %s", p.Target)
+	}
+	return resources, nil
+}
+
 // jsonPatchFromBytes loads a Json 6902 patch from
 // a bytes input
 func jsonPatchFromBytes(
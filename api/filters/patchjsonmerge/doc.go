@@ -0,0 +1,6 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package patchjsonmerge contains a kio.Filter implementation of
+// JSON Merge Patch, as specified by RFC 7386.
+package patchjsonmerge
@@ -0,0 +1,63 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package patchjsonmerge
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type Filter struct {
+	Patch *yaml.RNode
+}
+
+var _ kio.Filter = Filter{}
+
+// Filter applies a JSON merge patch to each node.  Unlike a
+// strategic merge patch, there are no directives or merge keys:
+// null values delete fields, maps merge and everything
+// else, including lists, replaces the original value.
+func (pf Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	if pf.Patch == nil || pf.Patch.YNode().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("a JSON merge patch must be an object")
+	}
+	return kio.FilterAll(yaml.FilterFunc(pf.run)).Filter(nodes)
+}
+
+func (pf Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	if err := yaml.ErrorIfInvalid(node, yaml.MappingNode); err != nil {
+		return nil, err
+	}
+	return merge(node, pf.Patch)
+}
+
+// merge returns the result of applying the patch to the target, per
+// the MergePatch function of RFC 7386.  The target is modified in
+// place when both it and the patch are maps.
+func merge(target, patch *yaml.RNode) (*yaml.RNode, error) {
+	if patch.YNode().Kind != yaml.MappingNode {
+		return patch.Copy(), nil
+	}
+	if target == nil || target.YNode().Kind != yaml.MappingNode {
+		target = yaml.NewMapRNode(nil)
+	}
+	err := patch.VisitFields(func(f *yaml.MapNode) error {
+		name := f.Key.YNode().Value
+		if f.Value.IsTaggedNull() {
+			return target.PipeE(yaml.Clear(name))
+		}
+		var current *yaml.RNode
+		if field := target.Field(name); field != nil {
+			current = field.Value
+		}
+		value, err := merge(current, f.Value)
+		if err != nil {
+			return err
+		}
+		return target.PipeE(yaml.SetField(name, value))
+	})
+	return target, err
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package patchjsonmerge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		patch    string
+		expected string
+	}{
+		"maps merge": {
+			input: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  size: 1
+  storage:
+    class: standard
+`,
+			patch: `
+spec:
+  storage:
+    capacity: 10Gi
+  paused: true
+`,
+			expected: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  size: 1
+  storage:
+    class: standard
+    capacity: 10Gi
+  paused: true
+`,
+		},
+		"null deletes": {
+			input: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+  labels:
+    app: foo
+    tier: web
+spec:
+  size: 1
+`,
+			patch: `
+metadata:
+  labels:
+    tier: null
+spec: ~
+status:
+`,
+			expected: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+  labels:
+    app: foo
+`,
+		},
+		"lists replace": {
+			// there are no merge keys, unlike in a strategic merge patch
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: sidecar
+`,
+			patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+`,
+		},
+		"scalars and maps replace each other": {
+			input: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  a: scalar
+  b:
+    c: d
+`,
+			patch: `
+spec:
+  a:
+    x: 1
+    y: null
+  b: scalar
+`,
+			expected: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  a:
+    x: 1
+  b: scalar
+`,
+		},
+		"directives aren't special": {
+			input: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  a: b
+`,
+			patch: `
+spec:
+  $patch: replace
+`,
+			expected: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  a: b
+  $patch: replace
+`,
+		},
+		"json": {
+			input: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  a: b
+`,
+			patch: `{"spec": {"a": null, "c": ["d"]}}`,
+			expected: `apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  c: ["d"]
+`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			f := Filter{Patch: yaml.MustParse(tc.patch)}
+			if !assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(filtertest.RunFilter(t, tc.input, f))) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestFilterPatchNotAnObject(t *testing.T) {
+	f := Filter{Patch: yaml.MustParse(`- a`)}
+	_, err := f.Filter([]*yaml.RNode{yaml.MustParse(`a: b`)})
+	if assert.Error(t, err) {
+		assert.Equal(t, "a JSON merge patch must be an object", err.Error())
+	}
+}
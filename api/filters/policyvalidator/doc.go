@@ -0,0 +1,7 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package policyvalidator contains a kio.Filter implementation of the
+// kustomize PolicyValidator, which checks resources against declarative
+// policy rules without modifying them.
+package policyvalidator
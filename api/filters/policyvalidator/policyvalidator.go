@@ -0,0 +1,228 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package policyvalidator
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/fieldspec"
+	"sigs.k8s.io/kustomize/api/internal/celexpr"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// containerFields hold lists of containers in workloads.
+var containerFields = []string{
+	"containers", "initContainers", "ephemeralContainers"}
+
+const defaultRegistry = "docker.io"
+
+// ViolationError lists the ways a node violates a rule.
+type ViolationError struct {
+	Violations []string
+}
+
+func (e *ViolationError) Error() string {
+	return strings.Join(e.Violations, "; ")
+}
+
+// Filter checks each node against the rule, and returns the nodes
+// unchanged, along with a *ViolationError if any of them violate it.
+// The rule's Target is ignored; it's up to the caller to select
+// the nodes the rule applies to.
+type Filter struct {
+	Rule types.PolicyRule
+}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	exprs := make([]*celexpr.Expression, len(f.Rule.Expressions))
+	for i, e := range f.Rule.Expressions {
+		var err error
+		exprs[i], err = celexpr.Compile(e.Expression)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var violations []string
+	for _, n := range nodes {
+		v, err := f.check(n, exprs)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, v...)
+	}
+	if len(violations) > 0 {
+		return nodes, &ViolationError{Violations: violations}
+	}
+	return nodes, nil
+}
+
+// check returns a message for each way the node violates the rule,
+// whose compiled expressions are exprs.
+func (f Filter) check(node *yaml.RNode, exprs []*celexpr.Expression) ([]string, error) {
+	var msgs []string
+	labels := node.GetLabels()
+	for _, k := range f.Rule.RequiredLabels {
+		if _, ok := labels[k]; !ok {
+			msgs = append(msgs, fmt.Sprintf("missing required label %q", k))
+		}
+	}
+	annotations := node.GetAnnotations()
+	for _, k := range f.Rule.RequiredAnnotations {
+		if _, ok := annotations[k]; !ok {
+			msgs = append(msgs, fmt.Sprintf("missing required annotation %q", k))
+		}
+	}
+	for _, ff := range f.Rule.ForbiddenFields {
+		found, err := findForbiddenField(ff, node)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, found...)
+	}
+	for i, e := range exprs {
+		if msg := checkExpression(e, f.Rule.Expressions[i].Message, node); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	if len(f.Rule.AllowedImageRegistries) == 0 &&
+		len(f.Rule.RequiredResourceLimits) == 0 {
+		return msgs, nil
+	}
+	for _, c := range findContainers(node) {
+		name := fieldValue(c, "name")
+		if len(f.Rule.AllowedImageRegistries) > 0 {
+			image := fieldValue(c, "image")
+			if !isImageAllowed(image, f.Rule.AllowedImageRegistries) {
+				msgs = append(msgs, fmt.Sprintf(
+					"container %q image %q is not from an allowed registry %v",
+					name, image, f.Rule.AllowedImageRegistries))
+			}
+		}
+		for _, l := range f.Rule.RequiredResourceLimits {
+			limit, err := c.Pipe(yaml.Lookup("resources", "limits", l))
+			if err != nil {
+				return nil, err
+			}
+			if yaml.IsMissingOrNull(limit) {
+				msgs = append(msgs, fmt.Sprintf(
+					"container %q has no %s limit", name, l))
+			}
+		}
+	}
+	return msgs, nil
+}
+
+// findForbiddenField returns a message for each occurrence of the field.
+func findForbiddenField(f types.ForbiddenField, node *yaml.RNode) ([]string, error) {
+	var msgs []string
+	err := node.PipeE(fieldspec.Filter{
+		FieldSpec: types.FieldSpec{Path: f.Path},
+		SetValue: func(n *yaml.RNode) error {
+			if yaml.IsMissingOrNull(n) {
+				return nil
+			}
+			if f.Value == "" {
+				msgs = append(msgs, fmt.Sprintf("forbidden field %s is set", f.Path))
+				return nil
+			}
+			if n.YNode().Kind == yaml.ScalarNode && n.YNode().Value == f.Value {
+				msgs = append(msgs, fmt.Sprintf(
+					"forbidden field %s is set to %s", f.Path, f.Value))
+			}
+			return nil
+		},
+	})
+	return msgs, err
+}
+
+// checkExpression returns a message if the expression isn't true
+// for the node, which is msg if set.
+func checkExpression(e *celexpr.Expression, msg string, node *yaml.RNode) string {
+	ok, err := e.Eval(node)
+	if err != nil {
+		return err.Error()
+	}
+	if ok {
+		return ""
+	}
+	if msg != "" {
+		return msg
+	}
+	return fmt.Sprintf("expression %q is false", e)
+}
+
+// findContainers returns the containers declared anywhere in the node,
+// e.g. in a Pod spec or a workload's pod template.
+func findContainers(node *yaml.RNode) []*yaml.RNode {
+	var result []*yaml.RNode
+	switch node.YNode().Kind {
+	case yaml.MappingNode:
+		_ = node.VisitFields(func(n *yaml.MapNode) error {
+			if isContainerField(n.Key.YNode().Value) &&
+				n.Value.YNode().Kind == yaml.SequenceNode {
+				elements, _ := n.Value.Elements()
+				for _, e := range elements {
+					if e.YNode().Kind == yaml.MappingNode {
+						result = append(result, e)
+					}
+				}
+				return nil
+			}
+			result = append(result, findContainers(n.Value)...)
+			return nil
+		})
+	case yaml.SequenceNode:
+		elements, _ := node.Elements()
+		for _, e := range elements {
+			result = append(result, findContainers(e)...)
+		}
+	}
+	return result
+}
+
+func fieldValue(node *yaml.RNode, field string) string {
+	f := node.Field(field)
+	if f == nil {
+		return ""
+	}
+	return yaml.GetValue(f.Value)
+}
+
+func isContainerField(field string) bool {
+	for _, f := range containerFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// isImageAllowed returns true if the image is from one of the
+// registries, each of which may also include a repository prefix.
+func isImageAllowed(image string, registries []string) bool {
+	image = normalizeImage(image)
+	for _, r := range registries {
+		r = strings.TrimSuffix(r, "/")
+		if strings.HasPrefix(image, r+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeImage prefixes images which don't name a registry
+// with the default registry, as the container runtime does.
+func normalizeImage(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return defaultRegistry + "/library/" + image
+	}
+	host := image[:i]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return defaultRegistry + "/" + image
+	}
+	return image
+}
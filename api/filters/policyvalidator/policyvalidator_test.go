@@ -0,0 +1,165 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package policyvalidator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+  labels:
+    app: dep
+  annotations:
+    owner: team-a
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      initContainers:
+      - name: init
+        image: gcr.io/my-project/init:v1
+        resources:
+          limits:
+            cpu: 100m
+      containers:
+      - name: app
+        image: nginx:1.19
+        securityContext:
+          privileged: false
+        resources:
+          limits:
+            memory: 1Gi
+      - name: sidecar
+        image: localhost:5000/sidecar
+`
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		rule       types.PolicyRule
+		violations []string
+	}{
+		"satisfied": {
+			rule: types.PolicyRule{
+				RequiredLabels:      []string{"app"},
+				RequiredAnnotations: []string{"owner"},
+				ForbiddenFields: []types.ForbiddenField{
+					{Path: "spec/template/spec/containers/securityContext/privileged", Value: "true"},
+					{Path: "spec/template/spec/hostPID"},
+				},
+				AllowedImageRegistries: []string{
+					"gcr.io/my-project", "docker.io", "localhost:5000"},
+			},
+		},
+		"labels and annotations": {
+			rule: types.PolicyRule{
+				RequiredLabels:      []string{"app", "tier"},
+				RequiredAnnotations: []string{"owner", "contact"},
+			},
+			violations: []string{
+				`missing required label "tier"`,
+				`missing required annotation "contact"`,
+			},
+		},
+		"forbidden fields": {
+			rule: types.PolicyRule{
+				ForbiddenFields: []types.ForbiddenField{
+					{Path: "spec/template/spec/hostNetwork"},
+					{Path: "spec/template/spec/containers/securityContext/privileged", Value: "false"},
+				},
+			},
+			violations: []string{
+				"forbidden field spec/template/spec/hostNetwork is set",
+				"forbidden field spec/template/spec/containers/securityContext/privileged is set to false",
+			},
+		},
+		"image registries": {
+			rule: types.PolicyRule{
+				AllowedImageRegistries: []string{"gcr.io/my-project/"},
+			},
+			violations: []string{
+				`container "app" image "nginx:1.19" is not from an allowed registry [gcr.io/my-project/]`,
+				`container "sidecar" image "localhost:5000/sidecar" is not from an allowed registry [gcr.io/my-project/]`,
+			},
+		},
+		"registry is not a prefix of the name": {
+			rule: types.PolicyRule{
+				AllowedImageRegistries: []string{"gcr.io/my", "docker.io/library", "localhost:5000"},
+			},
+			violations: []string{
+				`container "init" image "gcr.io/my-project/init:v1" is not from an allowed registry [gcr.io/my docker.io/library localhost:5000]`,
+			},
+		},
+		"resource limits": {
+			rule: types.PolicyRule{
+				RequiredResourceLimits: []string{"cpu", "memory"},
+			},
+			violations: []string{
+				`container "init" has no memory limit`,
+				`container "app" has no cpu limit`,
+				`container "sidecar" has no cpu limit`,
+				`container "sidecar" has no memory limit`,
+			},
+		},
+		"expressions": {
+			rule: types.PolicyRule{
+				Expressions: []types.PolicyExpression{
+					{Expression: "object.spec.template.spec.hostNetwork"},
+					{Expression: "has(object.spec.replicas)"},
+					{
+						Expression: "object.spec.template.spec.containers.size() <= 1",
+						Message:    "too many containers",
+					},
+					{Expression: "object.spec.replicas > 1"},
+				},
+			},
+			violations: []string{
+				`expression "has(object.spec.replicas)" is false`,
+				"too many containers",
+				`unable to evaluate expression "object.spec.replicas > 1": no such key: replicas`,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			node, err := yaml.Parse(deployment)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			nodes, err := Filter{Rule: tc.rule}.Filter([]*yaml.RNode{node})
+			assert.Len(t, nodes, 1)
+			if tc.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var ve *ViolationError
+			if !assert.True(t, errors.As(err, &ve)) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.violations, ve.Violations)
+		})
+	}
+}
+
+func TestFilterInvalidExpression(t *testing.T) {
+	node, err := yaml.Parse(deployment)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = Filter{Rule: types.PolicyRule{
+		Expressions: []types.PolicyExpression{{Expression: "object.kind +"}},
+	}}.Filter([]*yaml.RNode{node})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid expression "object.kind +"`)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strings"
 
+	"sigs.k8s.io/kustomize/api/internal/celexpr"
+	"sigs.k8s.io/kustomize/api/internal/fieldcond"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/resid"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -42,9 +44,19 @@ func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targets []*types.T
 		if len(t.FieldPaths) == 0 {
 			t.FieldPaths = []string{types.DefaultReplacementFieldPath}
 		}
+		sel, err := newNodeSelector(t.Select)
+		if err != nil {
+			return nil, err
+		}
+		rejects := make([]*nodeSelector, len(t.Reject))
+		for i := range t.Reject {
+			rejects[i], err = newNodeSelector(t.Reject[i])
+			if err != nil {
+				return nil, err
+			}
+		}
 		for _, n := range nodes {
-			id := makeResId(n)
-			if id.IsSelectedBy(t.Select.ResId) && !rejectId(t.Reject, id) {
+			if sel.matches(n) && !anyMatches(rejects, n) {
 				err := applyToNode(n, value, t)
 				if err != nil {
 					return nil, err
@@ -55,9 +67,44 @@ func applyReplacement(nodes []*yaml.RNode, value *yaml.RNode, targets []*types.T
 	return nodes, nil
 }
 
-func rejectId(rejects []*types.Selector, id *resid.ResId) bool {
-	for _, r := range rejects {
-		if id.IsSelectedBy(r.ResId) {
+// nodeSelector matches nodes by id and, optionally,
+// expression and field conditions.
+type nodeSelector struct {
+	id         resid.ResId
+	expr       *celexpr.Expression
+	conditions []*fieldcond.Condition
+}
+
+func newNodeSelector(s *types.Selector) (*nodeSelector, error) {
+	result := &nodeSelector{id: s.ResId}
+	if s.Expression != "" {
+		expr, err := celexpr.Compile(s.Expression)
+		if err != nil {
+			return nil, err
+		}
+		result.expr = expr
+	}
+	conditions, err := fieldcond.Parse(s.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	result.conditions = conditions
+	return result, nil
+}
+
+func (s *nodeSelector) matches(n *yaml.RNode) bool {
+	if !makeResId(n).IsSelectedBy(s.id) {
+		return false
+	}
+	if s.expr != nil && !s.expr.Matches(n) {
+		return false
+	}
+	return fieldcond.MatchesAll(s.conditions, n)
+}
+
+func anyMatches(selectors []*nodeSelector, n *yaml.RNode) bool {
+	for _, s := range selectors {
+		if s.matches(n) {
 			return true
 		}
 	}
@@ -1338,6 +1338,129 @@ spec:
 `,
 			expectedErr: "delimiter option can only be used with scalar nodes",
 		},
+		"select and reject by expression": {
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  zone: us-east1-b
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy1
+spec:
+  replicas: 3
+  template:
+    spec:
+      nodeSelector:
+        zone: unset
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy2
+spec:
+  replicas: 1
+  template:
+    spec:
+      nodeSelector:
+        zone: unset
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy3
+  labels:
+    pinned: "true"
+spec:
+  replicas: 3
+  template:
+    spec:
+      nodeSelector:
+        zone: unset
+`,
+			replacements: `replacements:
+- source:
+    kind: ConfigMap
+    name: config
+    fieldPath: data.zone
+  targets:
+  - select:
+      kind: Deployment
+      expression: object.spec.replicas > 1
+    reject:
+    - expression: object.metadata.labels.pinned == "true"
+    fieldPaths:
+    - spec.template.spec.nodeSelector.zone
+`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  zone: us-east1-b
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy1
+spec:
+  replicas: 3
+  template:
+    spec:
+      nodeSelector:
+        zone: us-east1-b
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy2
+spec:
+  replicas: 1
+  template:
+    spec:
+      nodeSelector:
+        zone: unset
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy3
+  labels:
+    pinned: "true"
+spec:
+  replicas: 3
+  template:
+    spec:
+      nodeSelector:
+        zone: unset
+`,
+		},
+		"invalid expression": {
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  zone: us-east1-b
+`,
+			replacements: `replacements:
+- source:
+    kind: ConfigMap
+    name: config
+    fieldPath: data.zone
+  targets:
+  - select:
+      expression: spec.replicas > 1
+    fieldPaths:
+    - data.zone
+`,
+			expectedErr: `invalid expression "spec.replicas > 1": ERROR: <input>:1:1: undeclared reference to 'spec' (in container '')
+ | spec.replicas > 1
+ | ^`,
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -3,11 +3,16 @@ module sigs.k8s.io/kustomize/api
 go 1.16
 
 require (
+	filippo.io/age v1.0.0-rc.3
+	github.com/BurntSushi/toml v1.2.1
 	github.com/evanphx/json-patch v4.5.0+incompatible
 	github.com/go-errors/errors v1.0.1
+	github.com/golang/protobuf v1.4.3
+	github.com/google/cel-go v0.7.3
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
 	github.com/imdario/mergo v0.3.5
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/stretchr/testify v1.5.1
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/kube-openapi v0.0.0-20210421082810-95288971da7e
@@ -4,11 +4,14 @@
 package hasher
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"sort"
 
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -20,17 +23,18 @@ func SortArrayAndComputeHash(s []string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return encode(hex256(string(data)))
+	return encode(hex256(string(data)), types.DefaultHashLength)
 }
 
 // Copied from https://github.com/kubernetes/kubernetes
 // /blob/master/pkg/kubectl/util/hash/hash.go
-func encode(hex string) (string, error) {
-	if len(hex) < 10 {
+// and extended to encode the first n characters.
+func encode(hex string, n int) (string, error) {
+	if len(hex) < n {
 		return "", fmt.Errorf(
-			"input length must be at least 10")
+			"input length must be at least %d", n)
 	}
-	enc := []rune(hex[:10])
+	enc := []rune(hex[:n])
 	for i := range enc {
 		switch enc[i] {
 		case '0':
@@ -53,8 +57,31 @@ func hex256(data string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
 }
 
+// hmac256 returns the hex form of the HMAC-SHA256
+// of the argument keyed with the salt.
+func hmac256(data, salt string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(data))
+	return fmt.Sprintf("%x", mac.Sum(nil))
+}
+
 // Hasher computes the hash of an RNode.
-type Hasher struct{}
+// Its zero value makes the default hashes.
+type Hasher struct {
+	length int
+	salt   string
+}
+
+var _ ifc.ConfigurableKustHasher = &Hasher{}
+
+// WithOptions returns a Hasher making hashes of the
+// given length, keyed with the given salt if any.
+func (h *Hasher) WithOptions(o types.HashOptions) (ifc.KustHasher, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return &Hasher{length: o.Length, salt: o.Salt}, nil
+}
 
 // Hash returns a hash of the argument.
 func (h *Hasher) Hash(node *yaml.RNode) (r string, err error) {
@@ -65,14 +92,23 @@ func (h *Hasher) Hash(node *yaml.RNode) (r string, err error) {
 	case "Secret":
 		encoded, err = encodeSecret(node)
 	default:
+		// The JSON of the fields, not of the node, so that comments,
+		// field order and style don't change the hash.
 		var encodedBytes []byte
-		encodedBytes, err = json.Marshal(node.YNode())
+		encodedBytes, err = node.MarshalJSON()
 		encoded = string(encodedBytes)
 	}
 	if err != nil {
 		return "", err
 	}
-	return encode(hex256(encoded))
+	length := h.length
+	if length == 0 {
+		length = types.DefaultHashLength
+	}
+	if h.salt != "" {
+		return encode(hmac256(encoded, h.salt), length)
+	}
+	return encode(hex256(encoded), length)
 }
 
 func getNodeValues(
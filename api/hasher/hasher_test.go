@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -180,7 +181,7 @@ func TestBasicHash(t *testing.T) {
 apiVersion: test/v1
 kind: TestResource
 metadata:
-  name: my-resource`, "244782mkb7", ""},
+  name: my-resource`, "2tt46d7f79", ""},
 		"with spec": {`
 apiVersion: test/v1
 kind: TestResource
@@ -188,7 +189,33 @@ metadata:
   name: my-resource
 spec:
   foo: 1
-  bar: abc`, "59m2mdccg4", ""},
+  bar: abc`, "6gc62g4m6k", ""},
+		"with spec, comments and blank lines": {`
+# a comment
+
+apiVersion: test/v1
+kind: TestResource
+metadata:
+  name: my-resource # the name
+spec:
+  foo: 1
+
+  bar: abc`, "6gc62g4m6k", ""},
+		"with spec in another order": {`
+spec:
+  bar: abc
+  foo: 1
+metadata:
+  name: my-resource
+kind: TestResource
+apiVersion: test/v1`, "6gc62g4m6k", ""},
+		"with spec in another style": {`
+apiVersion: "test/v1"
+kind: TestResource
+metadata: {name: my-resource}
+spec:
+    foo: 1
+    bar: 'abc'`, "6gc62g4m6k", ""},
 	}
 	h := &Hasher{}
 	for n := range cases {
@@ -203,7 +230,72 @@ spec:
 				return
 			}
 			if c.hash != hashed {
-				t.Errorf("case %q, expect hash %q but got %q", n, c.hash, h)
+				t.Errorf("case %q, expect hash %q but got %q", n, c.hash, hashed)
+			}
+		})
+	}
+}
+
+func TestHasherWithOptions(t *testing.T) {
+	cases := map[string]struct {
+		opts types.HashOptions
+		hash string
+		err  string
+	}{
+		"defaults": {
+			opts: types.HashOptions{},
+			hash: "2tt46d7f79",
+		},
+		"default length": {
+			opts: types.HashOptions{Length: types.DefaultHashLength},
+			hash: "2tt46d7f79",
+		},
+		"shorter": {
+			opts: types.HashOptions{Length: 6},
+			hash: "2tt46d",
+		},
+		"longer": {
+			opts: types.HashOptions{Length: 16},
+			hash: "2tt46d7f79k7946b",
+		},
+		"salted": {
+			opts: types.HashOptions{Salt: "pepper"},
+			hash: "k2759hkmck",
+		},
+		"salted and shorter": {
+			opts: types.HashOptions{Length: 5, Salt: "pepper"},
+			hash: "k2759",
+		},
+		"too short": {
+			opts: types.HashOptions{Length: 4},
+			err:  "hashLength 4 must be between 5 and 64",
+		},
+		"too long": {
+			opts: types.HashOptions{Length: 65},
+			err:  "hashLength 65 must be between 5 and 64",
+		},
+	}
+	node, err := yaml.Parse(`
+apiVersion: test/v1
+kind: TestResource
+metadata:
+  name: my-resource`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range cases {
+		c := cases[n]
+		t.Run(n, func(t *testing.T) {
+			h, err := (&Hasher{}).WithOptions(c.opts)
+			if SkipRest(t, n, err, c.err) {
+				return
+			}
+			hashed, err := h.Hash(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.hash != hashed {
+				t.Errorf("case %q, expect hash %q but got %q", n, c.hash, hashed)
 			}
 		})
 	}
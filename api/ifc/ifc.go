@@ -26,6 +26,24 @@ type KvLoader interface {
 	Load(args types.KvPairSources) (all []types.Pair, err error)
 }
 
+// EncryptedKvLoader is a KvLoader which can decrypt sources.
+type EncryptedKvLoader interface {
+	KvLoader
+	// LoadEncrypted decrypts the sources in memory,
+	// and returns their pairs.
+	LoadEncrypted(args types.EncryptedSources) ([]types.Pair, error)
+}
+
+// DirectoryKvLoader is a KvLoader which can tell
+// where the files of directory sources belong.
+type DirectoryKvLoader interface {
+	KvLoader
+	// DirectoryItems returns the keys the directory sources
+	// give their files, paired with the paths of the files
+	// in their trees.
+	DirectoryItems(sources []types.DirectorySource) ([]types.KeyToPath, error)
+}
+
 // Loader interface exposes methods to read bytes.
 type Loader interface {
 	// Root returns the root location for this Loader.
@@ -38,11 +56,43 @@ type Loader interface {
 	Cleanup() error
 }
 
+// GlobLoader is a Loader which can expand globs.
+type GlobLoader interface {
+	Loader
+	// Glob returns the sorted paths of the files matching the
+	// pattern, or in the directory it names, which Load may read.
+	Glob(pattern string) ([]string, error)
+}
+
+// TreeLoader is a Loader which can list directory trees.
+type TreeLoader interface {
+	Loader
+	// Tree returns the sorted, slash separated paths, relative
+	// to the directory, of the files in the tree under it.
+	Tree(dir string) ([]string, error)
+}
+
 // KustHasher returns a hash of the argument
 // or an error.
 type KustHasher interface {
 	Hash(*yaml.RNode) (string, error)
 }
 
+// ConfigurableKustHasher is a KustHasher whose hashes
+// generatorOptions can configure.
+type ConfigurableKustHasher interface {
+	KustHasher
+	// WithOptions returns a KustHasher configured by the
+	// options, or an error if it cannot honor them.
+	// Hashes under the zero options must not change.
+	WithOptions(types.HashOptions) (KustHasher, error)
+}
+
 // See core.v1.SecretTypeOpaque
 const SecretTypeOpaque = "Opaque"
+
+// See core.v1.SecretTypeTLS
+const SecretTypeTLS = "kubernetes.io/tls"
+
+// See core.v1.SecretTypeDockerConfigJson
+const SecretTypeDockerConfigJSON = "kubernetes.io/dockerconfigjson"
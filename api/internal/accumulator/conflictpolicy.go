@@ -0,0 +1,125 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package accumulator
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// AppendAllWithConflictPolicy appends the resources, resolving
+// conflicts with accumulated resources of the same id per the
+// policy, unless the resources' annotations set another one.
+func (ra *ResAccumulator) AppendAllWithConflictPolicy(
+	resources resmap.ResMap, policy types.ConflictPolicy) error {
+	if resources == nil {
+		return nil
+	}
+	for _, res := range resources.Resources() {
+		if err := ra.appendWithConflictPolicy(res, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ra *ResAccumulator) appendWithConflictPolicy(
+	res *resource.Resource, policy types.ConflictPolicy) error {
+	id := res.CurId()
+	matches := ra.resMap.GetMatchingResourcesByCurrentId(id.Equals)
+	if len(matches) != 1 {
+		// no conflict, or one Append reports
+		return ra.resMap.Append(res)
+	}
+	first := matches[0]
+	policy, err := resolveConflictPolicy(first, res, policy)
+	if err != nil {
+		return err
+	}
+	diff, err := diffResources(first, res)
+	if err != nil {
+		return err
+	}
+	switch policy {
+	case types.ConflictPolicyKeepFirst:
+		if diff != "" {
+			log.Printf("warning: keeping the first of the differing copies of %s:\n%s", id, diff)
+		}
+		return nil
+	case types.ConflictPolicyKeepLast:
+		if diff != "" {
+			log.Printf("warning: keeping the last of the differing copies of %s:\n%s", id, diff)
+		}
+		_, err = ra.resMap.Replace(res)
+		return err
+	case types.ConflictPolicyMergeIfEqual:
+		if diff == "" {
+			return nil
+		}
+		return fmt.Errorf("can't merge the differing copies of %s:\n%s", id, diff)
+	default:
+		err = ra.resMap.Append(res)
+		if diff != "" {
+			return fmt.Errorf("%v; the copies differ:\n%s", err, diff)
+		}
+		return err
+	}
+}
+
+// resolveConflictPolicy returns the policy set by the annotations of
+// the conflicting copies, which must agree, or else the given policy.
+func resolveConflictPolicy(
+	first, last *resource.Resource, policy types.ConflictPolicy) (types.ConflictPolicy, error) {
+	var annotated types.ConflictPolicy
+	for _, r := range []*resource.Resource{first, last} {
+		p := types.ConflictPolicy(r.GetAnnotations()[types.ConflictPolicyAnnotation])
+		if p == "" {
+			continue
+		}
+		if err := p.Validate(); err != nil {
+			return "", fmt.Errorf(
+				"annotation %s of %s: %w", types.ConflictPolicyAnnotation, r.CurId(), err)
+		}
+		if annotated != "" && annotated != p {
+			return "", fmt.Errorf(
+				"the copies of %s have the conflicting policies '%s' and '%s'",
+				r.CurId(), annotated, p)
+		}
+		annotated = p
+	}
+	if annotated != "" {
+		return annotated, nil
+	}
+	return policy, nil
+}
+
+// diffResources returns a unified diff of the resources,
+// ignoring build annotations, or "" if they're equal.
+func diffResources(first, last *resource.Resource) (string, error) {
+	var texts []string
+	for _, r := range []*resource.Resource{first, last} {
+		c := r.DeepCopy()
+		c.RemoveBuildAnnotations()
+		y, err := c.AsYAML()
+		if err != nil {
+			return "", err
+		}
+		texts = append(texts, string(y))
+	}
+	if texts[0] == texts[1] {
+		return "", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(texts[0]),
+		B:        difflib.SplitLines(texts[1]),
+		FromFile: "first",
+		ToFile:   "last",
+		Context:  3,
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package accumulator
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/fieldspec"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// checksumAnnotationPrefix prefixes the name of the resource
+// whose hash a pod template's checksum annotation holds.
+const checksumAnnotationPrefix = "checksum/"
+
+// podTemplatePaths are the paths to the pod templates
+// of workloads, most specific first.
+var podTemplatePaths = [][]string{
+	{"spec", "jobTemplate", "spec", "template"},
+	{"spec", "template"},
+}
+
+// AddContentChecksums stamps the hash of each resource annotated
+// for content checksums on the pod templates of the workloads
+// referring to it, as told by the name back references, in an
+// annotation named checksum/<resource name>.  Their pods then
+// roll when the resource changes, though its name doesn't.
+// Content hash suffixes are left to the HashTransformer.
+func (ra *ResAccumulator) AddContentChecksums(h ifc.KustHasher) error {
+	for _, r := range ra.resMap.Resources() {
+		annotations := r.GetAnnotations()
+		mode, ok := annotations[types.ContentHashAnnotation]
+		if !ok {
+			continue
+		}
+		if err := types.ContentHashMode(mode).Validate(); err != nil {
+			return fmt.Errorf("annotation %s of %s: %v",
+				types.ContentHashAnnotation, r.CurId(), err)
+		}
+		if types.ContentHashMode(mode) != types.ContentHashChecksum {
+			continue
+		}
+		delete(annotations, types.ContentHashAnnotation)
+		if err := r.SetAnnotations(annotations); err != nil {
+			return err
+		}
+		content := r.DeepCopy()
+		content.RemoveBuildAnnotations()
+		hash, err := content.Hash(h)
+		if err != nil {
+			return err
+		}
+		if err = ra.stampChecksum(r, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stampChecksum annotates the pod templates of the workloads
+// referring to the resource with its hash.
+func (ra *ResAccumulator) stampChecksum(r *resource.Resource, hash string) error {
+	// Referrers may still use any of the names the resource has had.
+	names := map[string]bool{r.GetName(): true}
+	for _, id := range r.PrevIds() {
+		names[id.Name] = true
+	}
+	for _, backRef := range ra.tConfig.NameReference {
+		if !r.GetGvk().IsSelected(&backRef.Gvk) {
+			continue
+		}
+		for _, fs := range backRef.Referrers {
+			for _, w := range ra.resMap.Resources() {
+				if w == r || (!r.GetGvk().IsClusterScoped() &&
+					w.GetNamespace() != r.GetNamespace()) {
+					continue
+				}
+				refers, err := refersToAny(w, fs, names)
+				if err != nil {
+					return err
+				}
+				if !refers {
+					continue
+				}
+				if err = setPodTemplateAnnotation(
+					w, checksumAnnotationPrefix+r.GetName(), hash); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// refersToAny tells whether a field of the referrer
+// given by the field spec holds one of the names.
+func refersToAny(
+	referrer *resource.Resource, fs types.FieldSpec, names map[string]bool) (bool, error) {
+	// Only look; don't create the field.
+	fs.CreateIfNotPresent = false
+	found := false
+	_, err := fieldspec.Filter{
+		FieldSpec: fs,
+		SetValue: func(node *yaml.RNode) error {
+			for _, name := range referredNames(node) {
+				if names[name] {
+					found = true
+				}
+			}
+			return nil
+		},
+	}.Filter(&referrer.RNode)
+	return found, err
+}
+
+// referredNames returns the names a referring field holds:
+// a name, a list of names, or objects with a name field.
+func referredNames(node *yaml.RNode) []string {
+	switch node.YNode().Kind {
+	case yaml.ScalarNode:
+		return []string{node.YNode().Value}
+	case yaml.MappingNode:
+		if n := node.Field("name"); n != nil {
+			return referredNames(n.Value)
+		}
+	case yaml.SequenceNode:
+		var names []string
+		for _, item := range node.Content() {
+			names = append(names, referredNames(yaml.NewRNode(item))...)
+		}
+		return names
+	}
+	return nil
+}
+
+// setPodTemplateAnnotation sets the annotation on the pod
+// template of the workload; workloads without one are skipped.
+func setPodTemplateAnnotation(w *resource.Resource, k, v string) error {
+	for _, path := range podTemplatePaths {
+		template, err := w.Pipe(yaml.Lookup(path...))
+		if err != nil {
+			return err
+		}
+		if template == nil {
+			continue
+		}
+		_, err = template.Pipe(
+			yaml.LookupCreate(yaml.MappingNode, yaml.MetadataField, yaml.AnnotationsField),
+			yaml.SetField(k, yaml.NewStringRNode(v)))
+		return err
+	}
+	return nil
+}
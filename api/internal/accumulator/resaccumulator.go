@@ -45,6 +45,16 @@ func (ra *ResAccumulator) AppendAll(resources resmap.ResMap) error {
 	return ra.resMap.AppendAll(resources)
 }
 
+// PrependAll puts the resources ahead of those accumulated.
+func (ra *ResAccumulator) PrependAll(resources resmap.ResMap) error {
+	m := resources.ShallowCopy()
+	if err := m.AppendAll(ra.resMap); err != nil {
+		return err
+	}
+	ra.resMap = m
+	return nil
+}
+
 func (ra *ResAccumulator) AbsorbAll(resources resmap.ResMap) error {
 	return ra.resMap.AbsorbAll(resources)
 }
@@ -92,7 +102,14 @@ func (ra *ResAccumulator) MergeVars(incoming []types.Var) error {
 }
 
 func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
-	err = ra.AppendAll(other.resMap)
+	return ra.MergeAccumulatorWithConflictPolicy(other, types.ConflictPolicyError)
+}
+
+// MergeAccumulatorWithConflictPolicy merges the other accumulator,
+// resolving conflicting resources per the policy.
+func (ra *ResAccumulator) MergeAccumulatorWithConflictPolicy(
+	other *ResAccumulator, policy types.ConflictPolicy) (err error) {
+	err = ra.AppendAllWithConflictPolicy(other.resMap, policy)
 	if err != nil {
 		return err
 	}
@@ -387,6 +387,37 @@ func TestResolveVarsWithNoambiguation(t *testing.T) {
 	}
 }
 
+func TestPrependAll(t *testing.T) {
+	ra := makeResAccumulator(t)
+	rm := resmap.New()
+	err := rm.Append(
+		provider.NewDefaultDepProvider().GetResourceFactory().FromMap(
+			map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata": map[string]interface{}{
+					"name": "team-a",
+				},
+			}))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	err = ra.PrependAll(rm)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	resources := ra.ResMap().Resources()
+	if len(resources) != 4 || resources[0].GetName() != "team-a" {
+		t.Fatalf("expected team-a ahead of the 3 others, got %v",
+			ra.ResMap().AllIds())
+	}
+	err = ra.PrependAll(rm)
+	if err == nil || !strings.Contains(
+		err.Error(), "may not add resource with an already registered id") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
 func find(name string, resMap resmap.ResMap) *resource.Resource {
 	for _, r := range resMap.Resources() {
 		if r.GetName() == name {
@@ -0,0 +1,87 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package celexpr evaluates CEL expressions against resources.
+// See https://github.com/google/cel-spec for the language.
+package celexpr
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ObjectVariable is the name under which the resource
+// is available to expressions.
+const ObjectVariable = "object"
+
+// Expression is a compiled boolean CEL expression over a resource,
+// e.g. object.spec.replicas > 1 && has(object.spec.template).
+type Expression struct {
+	source  string
+	program cel.Program
+}
+
+// Compile parses and checks the expression, which must evaluate
+// to a boolean, or be of dynamic type.
+func Compile(source string) (*Expression, error) {
+	env, err := cel.NewEnv(
+		cel.Declarations(decls.NewVar(ObjectVariable, decls.Dyn)))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf(
+			"invalid expression %q: %w", source, issues.Err())
+	}
+	if t := ast.ResultType(); !proto.Equal(t, decls.Bool) &&
+		!proto.Equal(t, decls.Dyn) {
+		return nil, fmt.Errorf(
+			"expression %q must evaluate to a bool", source)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"invalid expression %q: %w", source, err)
+	}
+	return &Expression{source: source, program: program}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval returns the value of the expression for the node.
+// It's an error to access a field the node doesn't have, which
+// expressions avoid by testing for the field with has().
+func (e *Expression) Eval(node *yaml.RNode) (bool, error) {
+	object, err := node.Map()
+	if err != nil {
+		return false, err
+	}
+	v, _, err := e.program.Eval(map[string]interface{}{
+		ObjectVariable: object,
+	})
+	if err != nil {
+		return false, fmt.Errorf(
+			"unable to evaluate expression %q: %w", e.source, err)
+	}
+	b, ok := v.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf(
+			"expression %q evaluated to %v, not a bool", e.source, v.Value())
+	}
+	return b, nil
+}
+
+// Matches returns true if the expression is true for the node.
+// Evaluation errors, e.g. from accessing a field which
+// isn't set, mean the node doesn't match.
+func (e *Expression) Matches(node *yaml.RNode) bool {
+	b, err := e.Eval(node)
+	return err == nil && b
+}
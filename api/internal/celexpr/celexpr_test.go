@@ -0,0 +1,109 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package celexpr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/internal/celexpr"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      nodeSelector:
+        disk: ssd
+      containers:
+      - name: nginx
+        image: nginx:1.19
+`
+
+func TestEval(t *testing.T) {
+	testCases := map[string]struct {
+		expression string
+		expected   bool
+		err        string
+	}{
+		"comparison": {
+			expression: "object.spec.replicas > 1",
+			expected:   true,
+		},
+		"has": {
+			expression: "object.spec.replicas > 1 && has(object.spec.template.spec.nodeSelector)",
+			expected:   true,
+		},
+		"has missing": {
+			expression: "has(object.spec.strategy)",
+			expected:   false,
+		},
+		"string functions": {
+			expression: `object.kind == "Deployment" && object.metadata.name.startsWith("w")`,
+			expected:   true,
+		},
+		"labels": {
+			expression: `"app" in object.metadata.labels && object.metadata.labels.app != "web"`,
+			expected:   false,
+		},
+		"list macros": {
+			expression: `object.spec.template.spec.containers.all(c, c.image.contains(":"))`,
+			expected:   true,
+		},
+		"missing field": {
+			expression: "object.spec.strategy.type == 'Recreate'",
+			err:        `unable to evaluate expression "object.spec.strategy.type == 'Recreate'": no such key: strategy`,
+		},
+		"not a bool": {
+			expression: "object.metadata.name",
+			err:        `expression "object.metadata.name" evaluated to web, not a bool`,
+		},
+	}
+	node, err := yaml.Parse(deployment)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			e, err := Compile(tc.expression)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			actual, err := e.Eval(node)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, tc.err, err.Error())
+				}
+				assert.False(t, e.Matches(node))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.expected, e.Matches(node))
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile("object.spec.replicas >")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid expression "object.spec.replicas >"`)
+	}
+	_, err = Compile("1 + 2")
+	if assert.Error(t, err) {
+		assert.Equal(t, `expression "1 + 2" must evaluate to a bool`, err.Error())
+	}
+	_, err = Compile("spec.replicas > 1")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "undeclared reference to 'spec'")
+	}
+}
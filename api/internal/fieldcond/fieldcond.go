@@ -0,0 +1,185 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fieldcond evaluates conditions on the values
+// of resource fields, as used by selectors.
+package fieldcond
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// operator says how a condition constrains its field.
+type operator int
+
+const (
+	opExists operator = iota
+	opAbsent
+	opEquals
+	opMatches
+)
+
+// Condition is a parsed condition of a field selector.
+type Condition struct {
+	source string
+	path   []string
+	op     operator
+	value  string
+	regex  *regexp.Regexp
+}
+
+// Parse parses the comma separated conditions of
+// a field selector, as documented by types.Selector.
+// An empty selector has no conditions.
+func Parse(selector string) ([]*Condition, error) {
+	var result []*Condition
+	p := &parser{in: []rune(selector)}
+	for p.pos < len(p.in) {
+		c, err := p.condition()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid field selector %q: %w", selector, err)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// parser reads the conditions of a field selector.
+type parser struct {
+	in  []rune
+	pos int
+}
+
+// condition reads a condition and the comma ending it, if any.
+func (p *parser) condition() (*Condition, error) {
+	start := p.pos
+	c := &Condition{}
+	if p.in[p.pos] == '!' {
+		c.op = opAbsent
+		p.pos++
+	}
+	var elem strings.Builder
+	depth := 0
+	endPath := func() error {
+		if elem.Len() == 0 {
+			return fmt.Errorf("empty path element")
+		}
+		c.path = append(c.path, elem.String())
+		elem.Reset()
+		return nil
+	}
+	for ; p.pos < len(p.in); p.pos++ {
+		r := p.in[p.pos]
+		if r == '\\' {
+			if p.pos++; p.pos == len(p.in) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			elem.WriteRune(p.in[p.pos])
+			continue
+		}
+		if depth > 0 {
+			if r == ']' {
+				depth--
+			}
+			elem.WriteRune(r)
+			continue
+		}
+		switch r {
+		case '[':
+			depth++
+			elem.WriteRune(r)
+			continue
+		case '.':
+			if err := endPath(); err != nil {
+				return nil, err
+			}
+			continue
+		case ',', '=', '~':
+		default:
+			elem.WriteRune(r)
+			continue
+		}
+		break
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("unterminated [ in path")
+	}
+	if err := endPath(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.in) && p.in[p.pos] != ',' {
+		if c.op == opAbsent {
+			return nil, fmt.Errorf(
+				"an absent field can't have a value")
+		}
+		c.op = opEquals
+		if p.in[p.pos] == '~' {
+			c.op = opMatches
+		}
+		p.pos++
+		var value strings.Builder
+		for ; p.pos < len(p.in) && p.in[p.pos] != ','; p.pos++ {
+			if p.in[p.pos] == '\\' {
+				if p.pos++; p.pos == len(p.in) {
+					return nil, fmt.Errorf("trailing backslash")
+				}
+			}
+			value.WriteRune(p.in[p.pos])
+		}
+		c.value = value.String()
+	}
+	c.source = string(p.in[start:p.pos])
+	// skip the comma
+	p.pos++
+	if c.op == opMatches {
+		var err error
+		c.regex, err = regexp.Compile("^(?:" + c.value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex in %q: %w", c.source, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Matches returns true if the condition holds for the node.
+// Values and regexes only match scalar fields.
+func (c *Condition) Matches(node *yaml.RNode) bool {
+	field, err := node.Pipe(yaml.Lookup(c.path...))
+	if err != nil || yaml.IsMissingOrNull(field) {
+		// a path which can't be followed leads to no field
+		return c.op == opAbsent
+	}
+	switch c.op {
+	case opExists:
+		return true
+	case opAbsent:
+		return false
+	}
+	if field.YNode().Kind != yaml.ScalarNode {
+		return false
+	}
+	value := field.YNode().Value
+	if c.op == opEquals {
+		return value == c.value
+	}
+	return c.regex.MatchString(value)
+}
+
+// MatchesAll returns true if all of the conditions hold for the node.
+func MatchesAll(conditions []*Condition, node *yaml.RNode) bool {
+	for _, c := range conditions {
+		if !c.Matches(node) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fieldcond_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/internal/fieldcond"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const service = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    owner: null
+    example.com/team: web
+spec:
+  type: LoadBalancer
+  selector:
+    app: web
+  externalName: ""
+  loadBalancerSourceRanges:
+  - 10.0.0.0/8,192.168.0.0/16
+  ports:
+  - name: http
+    port: 80
+  - name: https
+    port: 443
+  - name: metrics.v1
+    port: 9090
+`
+
+func TestMatches(t *testing.T) {
+	testCases := map[string]struct {
+		selector string
+		expected bool
+	}{
+		"equal": {
+			selector: "spec.type=LoadBalancer",
+			expected: true,
+		},
+		"not equal": {
+			selector: "spec.type=ClusterIP",
+			expected: false,
+		},
+		"empty value": {
+			selector: "spec.externalName=",
+			expected: true,
+		},
+		"empty value of missing field": {
+			selector: "spec.clusterIP=",
+			expected: false,
+		},
+		"empty value of non empty field": {
+			selector: "spec.type=",
+			expected: false,
+		},
+		"list element": {
+			selector: "spec.ports.[name=https].port=443",
+			expected: true,
+		},
+		"list element with dots": {
+			selector: "spec.ports.[name=metrics.v1].port=9090",
+			expected: true,
+		},
+		"escaped comma in value": {
+			selector: `spec.loadBalancerSourceRanges.[=10.0.0.0/8\,192.168.0.0/16]`,
+			expected: true,
+		},
+		"escaped dot in path": {
+			selector: `metadata.annotations.example\.com/team=web`,
+			expected: true,
+		},
+		"exists": {
+			selector: "spec.selector",
+			expected: true,
+		},
+		"missing": {
+			selector: "spec.clusterIP",
+			expected: false,
+		},
+		"absent": {
+			selector: "!spec.clusterIP",
+			expected: true,
+		},
+		"null is absent": {
+			selector: "!metadata.annotations.owner",
+			expected: true,
+		},
+		"present is not absent": {
+			selector: "!spec.type",
+			expected: false,
+		},
+		"path through scalar is absent": {
+			selector: "!spec.type.name",
+			expected: true,
+		},
+		"regex": {
+			selector: "spec.type~Load.*|NodePort",
+			expected: true,
+		},
+		"regex is anchored": {
+			selector: "spec.type~Load",
+			expected: false,
+		},
+		"value of non scalar": {
+			selector: "spec.selector=web",
+			expected: false,
+		},
+		"all hold": {
+			selector: "kind=Service,spec.type=LoadBalancer,!spec.clusterIP",
+			expected: true,
+		},
+		"one fails": {
+			selector: "kind=Service,spec.type=NodePort",
+			expected: false,
+		},
+		"none": {
+			selector: "",
+			expected: true,
+		},
+	}
+	node := yaml.MustParse(service)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			conditions, err := Parse(tc.selector)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expected, MatchesAll(conditions, node))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	conditions, err := Parse(`a.[b=c.d]=x\,y,!e,f~g.*`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var sources []string
+	for _, c := range conditions {
+		sources = append(sources, c.String())
+	}
+	assert.Equal(t, []string{`a.[b=c.d]=x\,y`, "!e", "f~g.*"}, sources)
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := map[string]struct {
+		selector string
+		err      string
+	}{
+		"no path": {
+			selector: "=x",
+			err:      "empty path element",
+		},
+		"empty path element": {
+			selector: "spec..type",
+			err:      "empty path element",
+		},
+		"empty condition": {
+			selector: "spec.type=x,,kind",
+			err:      "empty path element",
+		},
+		"absent with value": {
+			selector: "!spec.type=x",
+			err:      "an absent field can't have a value",
+		},
+		"unterminated bracket": {
+			selector: "spec.ports.[name=http.port",
+			err:      "unterminated [ in path",
+		},
+		"trailing backslash": {
+			selector: `spec.type=x\`,
+			err:      "trailing backslash",
+		},
+		"invalid regex": {
+			selector: "spec.type~(",
+			err:      `invalid regex in "spec.type~("`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tc.selector)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package generators
 
 import (
+	"github.com/go-errors/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -33,6 +34,12 @@ func MakeConfigMap(
 	if err != nil {
 		return nil, err
 	}
+	if err = validateMergeKeys(ldr.Validator(), &args.GeneratorArgs); err != nil {
+		return nil, err
+	}
+	if err = args.Options.Validate(); err != nil {
+		return nil, errors.WrapPrefix(err, args.Name, 0)
+	}
 	m, err := makeValidatedDataMap(ldr, args.Name, args.KvPairSources)
 	if err != nil {
 		return nil, err
@@ -42,5 +49,8 @@ func MakeConfigMap(
 	}
 	copyLabelsAndAnnotations(rn, args.Options)
 	setImmutable(rn, args.Options)
+	if err = setVolumeItems(rn, ldr, args.DirectorySources); err != nil {
+		return nil, err
+	}
 	return rn, nil
 }
@@ -188,6 +188,44 @@ immutable: true
 `,
 			},
 		},
+		"remove keys without merge": {
+			args: types.ConfigMapArgs{
+				GeneratorArgs: types.GeneratorArgs{
+					Name:       "cm",
+					Behavior:   "replace",
+					RemoveKeys: []string{"a"},
+				},
+			},
+			exp: expected{
+				errMsg: "cm: removeKeys and renameKeys require the merge behavior",
+			},
+		},
+		"rename keys to the same key": {
+			args: types.ConfigMapArgs{
+				GeneratorArgs: types.GeneratorArgs{
+					Name:       "cm",
+					Behavior:   "merge",
+					RenameKeys: map[string]string{"a": "c", "b": "c"},
+				},
+			},
+			exp: expected{
+				errMsg: "cm: keys 'a' and 'b' are both renamed to 'c'",
+			},
+		},
+		"hash length out of bounds without a hash": {
+			args: types.ConfigMapArgs{
+				GeneratorArgs: types.GeneratorArgs{
+					Name: "cm",
+					Options: &types.GeneratorOptions{
+						DisableNameSuffixHash: true,
+						HashLength:            3,
+					},
+				},
+			},
+			exp: expected{
+				errMsg: "cm: hashLength 3 must be between 5 and 64",
+			},
+		},
 	}
 	fSys := filesys.MakeFsInMemory()
 	fSys.WriteFile(
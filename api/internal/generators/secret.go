@@ -4,6 +4,7 @@
 package generators
 
 import (
+	"github.com/go-errors/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -30,6 +31,15 @@ import (
 // client, to choose the algorithm to interpret the `data` field.  Kubernetes
 // cannot make use of this data; it's up to a controller or some pod's service
 // to interpret the value, using `type` as a clue as to how to do this.
+//
+// A few types get help here: the `.dockerconfigjson` of a Secret of type
+// `kubernetes.io/dockerconfigjson` may be built from a list of registries,
+// and must be JSON, and the certificate and key of a Secret of type
+// `kubernetes.io/tls` must match.
+//
+// The Secret is always made with a `data` field.  An output asking for
+// `stringData`, or a SealedSecret, is stamped on it, to be applied at
+// the end of the build, when its name and namespace are final.
 func MakeSecret(
 	ldr ifc.KvLoader, args *types.SecretArgs) (rn *yaml.RNode, err error) {
 	rn, err = makeBaseNode("Secret", args.Name, args.Namespace)
@@ -46,14 +56,60 @@ func MakeSecret(
 			Value: yaml.NewStringRNode(t)}); err != nil {
 		return nil, err
 	}
-	m, err := makeValidatedDataMap(ldr, args.Name, args.KvPairSources)
+	if err = validateMergeKeys(ldr.Validator(), &args.GeneratorArgs); err != nil {
+		return nil, err
+	}
+	if err = args.Options.Validate(); err != nil {
+		return nil, errors.WrapPrefix(err, args.Name, 0)
+	}
+	pairs, err := ldr.Load(args.KvPairSources)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "loading KV pairs", 0)
+	}
+	if args.Encrypted != nil {
+		el, ok := ldr.(ifc.EncryptedKvLoader)
+		if !ok {
+			return nil, errors.Errorf(
+				"secret %s has encrypted sources, which %T can't decrypt",
+				args.Name, ldr)
+		}
+		more, err := el.LoadEncrypted(*args.Encrypted)
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "loading encrypted KV pairs", 0)
+		}
+		pairs = append(pairs, more...)
+	}
+	if len(args.Registries) > 0 {
+		p, err := makeDockerConfigJSON(ldr, args)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+	m, err := makeValidatedMap(ldr.Validator(), args.Name, pairs)
 	if err != nil {
 		return nil, err
 	}
+	if err = validateTypedSecret(args, m); err != nil {
+		return nil, err
+	}
 	if err = rn.LoadMapIntoSecretData(m); err != nil {
 		return nil, err
 	}
 	copyLabelsAndAnnotations(rn, args.Options)
 	setImmutable(rn, args.Options)
+	if err = setVolumeItems(rn, ldr, args.DirectorySources); err != nil {
+		return nil, err
+	}
+	if args.Output != nil {
+		c, err := MakeSecretOutputConfig(args.Output,
+			func(path string) ([]byte, error) { return loadFile(ldr, path) })
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "secret output", 0)
+		}
+		if err = setSecretOutput(rn, c); err != nil {
+			return nil, err
+		}
+	}
 	return rn, nil
 }
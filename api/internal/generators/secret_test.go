@@ -4,11 +4,24 @@
 package generators_test
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log"
+	"math/big"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
 	. "sigs.k8s.io/kustomize/api/internal/generators"
 	"sigs.k8s.io/kustomize/api/kv"
 	"sigs.k8s.io/kustomize/api/loader"
@@ -196,8 +209,99 @@ immutable: true
 `,
 			},
 		},
+		"construct docker config secret from registries": {
+			args: types.SecretArgs{
+				GeneratorArgs: types.GeneratorArgs{Name: "regcred"},
+				Type:          "kubernetes.io/dockerconfigjson",
+				Registries: []types.DockerRegistry{
+					{
+						Registry:     "ghcr.io",
+						Username:     "bot",
+						PasswordFile: filepath.Join("secret", "ghcr.token"),
+					},
+					{
+						Registry:     "https://index.docker.io/v1/",
+						Username:     "admin",
+						PasswordFile: filepath.Join("secret", "docker.password"),
+						Email:        "admin@example.com",
+					},
+				},
+			},
+			exp: expected{
+				out: `apiVersion: v1
+kind: Secret
+metadata:
+  name: regcred
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: |
+    eyJhdXRocyI6eyJnaGNyLmlvIjp7InVzZXJuYW1lIjoiYm90IiwicGFzc3dvcmQiOiJ0b2
+    tlbiIsImF1dGgiOiJZbTkwT25SdmEyVnUifSwiaHR0cHM6Ly9pbmRleC5kb2NrZXIuaW8v
+    djEvIjp7InVzZXJuYW1lIjoiYWRtaW4iLCJwYXNzd29yZCI6InF3ZXJ0eSIsImVtYWlsIj
+    oiYWRtaW5AZXhhbXBsZS5jb20iLCJhdXRoIjoiWVdSdGFXNDZjWGRsY25SNSJ9fX0=
+`,
+			},
+		},
+		"registries need the docker config type": {
+			args: types.SecretArgs{
+				GeneratorArgs: types.GeneratorArgs{Name: "regcred"},
+				Registries: []types.DockerRegistry{{
+					Registry:     "ghcr.io",
+					Username:     "bot",
+					PasswordFile: filepath.Join("secret", "ghcr.token"),
+				}},
+			},
+			exp: expected{
+				errMsg: "secret regcred has registries, but isn't of type kubernetes.io/dockerconfigjson",
+			},
+		},
+		"registries need a password file": {
+			args: types.SecretArgs{
+				GeneratorArgs: types.GeneratorArgs{Name: "regcred"},
+				Type:          "kubernetes.io/dockerconfigjson",
+				Registries:    []types.DockerRegistry{{Registry: "ghcr.io", Username: "bot"}},
+			},
+			exp: expected{
+				errMsg: "registry #0 of secret regcred must have a registry, username and passwordFile",
+			},
+		},
+		"docker config secret without docker config": {
+			args: types.SecretArgs{
+				GeneratorArgs: types.GeneratorArgs{
+					Name: "regcred",
+					KvPairSources: types.KvPairSources{
+						LiteralSources: []string{"a=b"},
+					},
+				},
+				Type: "kubernetes.io/dockerconfigjson",
+			},
+			exp: expected{
+				errMsg: "secret regcred of type kubernetes.io/dockerconfigjson must have registries or the key .dockerconfigjson",
+			},
+		},
+		"docker config secret with invalid docker config": {
+			args: types.SecretArgs{
+				GeneratorArgs: types.GeneratorArgs{
+					Name: "regcred",
+					KvPairSources: types.KvPairSources{
+						LiteralSources: []string{".dockerconfigjson={auths"},
+					},
+				},
+				Type: "kubernetes.io/dockerconfigjson",
+			},
+			exp: expected{
+				errMsg: "the .dockerconfigjson of secret regcred isn't valid JSON: " +
+					"invalid character 'a' looking for beginning of object key string",
+			},
+		},
 	}
 	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(
+		filesys.RootedPath("secret", "ghcr.token"),
+		[]byte("token\n"))
+	fSys.WriteFile(
+		filesys.RootedPath("secret", "docker.password"),
+		[]byte("qwerty"))
 	fSys.WriteFile(
 		filesys.RootedPath("secret", "app.env"),
 		[]byte("DB_USERNAME=admin\nDB_PASSWORD=qwerty\n"))
@@ -231,3 +335,142 @@ immutable: true
 		})
 	}
 }
+
+// plainKvLoader has only the methods of ifc.KvLoader.
+type plainKvLoader struct {
+	ifc.KvLoader
+}
+
+func TestMakeSecretEncryptedWithoutDecryption(t *testing.T) {
+	kvLdr := plainKvLoader{kv.NewLoader(
+		loader.NewFileLoaderAtRoot(filesys.MakeFsInMemory()),
+		valtest_test.MakeFakeValidator())}
+	_, err := MakeSecret(kvLdr, &types.SecretArgs{
+		GeneratorArgs: types.GeneratorArgs{Name: "db"},
+		Encrypted: &types.EncryptedSources{
+			FileSources: []string{"db.yaml"},
+		},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"secret db has encrypted sources, which generators_test.plainKvLoader can't decrypt")
+	}
+}
+
+// makeTLSPair returns a self-signed PEM certificate,
+// valid until notAfter, and its PEM key.
+func makeTLSPair(t *testing.T, notAfter time.Time) (crt, key []byte) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(
+		rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestMakeTLSSecret(t *testing.T) {
+	now := time.Now()
+	crt, key := makeTLSPair(t, now.Add(365*24*time.Hour))
+	_, otherKey := makeTLSPair(t, now.Add(365*24*time.Hour))
+	expiringCrt, expiringKey := makeTLSPair(t, now.Add(24*time.Hour))
+	expiredCrt, expiredKey := makeTLSPair(t, now.Add(-24*time.Hour))
+
+	fSys := filesys.MakeFsInMemory()
+	for name, content := range map[string][]byte{
+		"tls.crt":        crt,
+		"tls.key":        key,
+		"other.key":      otherKey,
+		"expiring.crt":   expiringCrt,
+		"expiring.key":   expiringKey,
+		"expired.crt":    expiredCrt,
+		"expired.key":    expiredKey,
+		"not-a-cert.crt": []byte("hello"),
+		"not-a-cert.key": key,
+	} {
+		fSys.WriteFile(name, content)
+	}
+	kvLdr := kv.NewLoader(
+		loader.NewFileLoaderAtRoot(fSys),
+		valtest_test.MakeFakeValidator())
+
+	testCases := map[string]struct {
+		files    []string
+		behavior string
+		warning  string
+		errMsg   string
+	}{
+		"matching": {
+			files: []string{"tls.crt", "tls.key"},
+		},
+		"mismatched": {
+			files:  []string{"tls.crt", "tls.key=other.key"},
+			errMsg: "invalid TLS certificate and key in secret tls: tls: private key does not match public key",
+		},
+		"not a certificate": {
+			files:  []string{"tls.crt=not-a-cert.crt", "tls.key=not-a-cert.key"},
+			errMsg: "invalid TLS certificate and key in secret tls: tls: failed to find any PEM data in certificate input",
+		},
+		"expiring": {
+			files:   []string{"tls.crt=expiring.crt", "tls.key=expiring.key"},
+			warning: "warning: the TLS certificate in secret tls expires at",
+		},
+		"expired": {
+			files:   []string{"tls.crt=expired.crt", "tls.key=expired.key"},
+			warning: "warning: the TLS certificate in secret tls expired at",
+		},
+		"missing key": {
+			files:  []string{"tls.crt"},
+			errMsg: "secret tls of type kubernetes.io/tls must have the keys tls.crt and tls.key",
+		},
+		"missing key in merge": {
+			files:    []string{"tls.crt"},
+			behavior: "merge",
+		},
+	}
+	for n := range testCases {
+		tc := testCases[n]
+		t.Run(n, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+			_, err := MakeSecret(kvLdr, &types.SecretArgs{
+				GeneratorArgs: types.GeneratorArgs{
+					Name:          "tls",
+					Behavior:      tc.behavior,
+					KvPairSources: types.KvPairSources{FileSources: tc.files},
+				},
+				Type: "kubernetes.io/tls",
+			})
+			if tc.errMsg != "" {
+				if !assert.EqualError(t, err, tc.errMsg) {
+					t.FailNow()
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if tc.warning == "" {
+				assert.Empty(t, logs.String())
+			} else {
+				assert.True(t, strings.Contains(logs.String(), tc.warning), logs.String())
+			}
+		})
+	}
+}
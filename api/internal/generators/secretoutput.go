@@ -0,0 +1,315 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package generators
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	sealedSecretAPIVersion = "bitnami.com/v1alpha1"
+	sealedSecretKind       = "SealedSecret"
+	// The sealed secrets controller's session keys are AES-256 keys.
+	sealingSessionKeyBytes = 32
+)
+
+// sealingScopeAnnotations tell the controller
+// the scope of the SealedSecret, if not strict.
+var sealingScopeAnnotations = map[types.SealingScope]string{
+	types.SealingScopeNamespaceWide: "sealedsecrets.bitnami.com/namespace-wide",
+	types.SealingScopeClusterWide:   "sealedsecrets.bitnami.com/cluster-wide",
+}
+
+// SecretOutputConfig is a validated SecretOutput,
+// with the certificate to seal with loaded.
+type SecretOutputConfig struct {
+	// Format and Scope are never empty.
+	Format types.SecretOutputFormat `json:"format"`
+	Scope  types.SealingScope       `json:"scope,omitempty"`
+	// Certificate is PEM encoded.
+	Certificate string `json:"certificate,omitempty"`
+
+	key *rsa.PublicKey
+}
+
+// MakeSecretOutputConfig validates the output, and
+// loads its certificate, if any, with the function.
+func MakeSecretOutputConfig(
+	o *types.SecretOutput,
+	load func(path string) ([]byte, error)) (*SecretOutputConfig, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	c := &SecretOutputConfig{Format: o.Format, Scope: o.Scope}
+	if c.Format == "" {
+		c.Format = types.SecretOutputData
+	}
+	if c.Format != types.SecretOutputSealed {
+		return c, nil
+	}
+	if c.Scope == "" {
+		c.Scope = types.SealingScopeStrict
+	}
+	pemBytes, err := load(o.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	c.Certificate = string(pemBytes)
+	if c.key, err = parseSealingKey(pemBytes); err != nil {
+		return nil, fmt.Errorf("certificate %s: %v", o.Certificate, err)
+	}
+	return c, nil
+}
+
+// SecretOutputConfigFromAnnotations returns the output config
+// the generator of a Secret stamped on it, or nil if none.
+func SecretOutputConfigFromAnnotations(
+	annotations map[string]string) (*SecretOutputConfig, error) {
+	v, ok := annotations[types.SecretOutputAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	c := &SecretOutputConfig{}
+	if err := json.Unmarshal([]byte(v), c); err != nil {
+		return nil, fmt.Errorf("annotation %s: %v", types.SecretOutputAnnotation, err)
+	}
+	if c.Format != types.SecretOutputSealed {
+		return c, nil
+	}
+	var err error
+	if c.key, err = parseSealingKey([]byte(c.Certificate)); err != nil {
+		return nil, fmt.Errorf("annotation %s: %v", types.SecretOutputAnnotation, err)
+	}
+	return c, nil
+}
+
+// setSecretOutput stamps the output config on the Secret.
+func setSecretOutput(rn *yaml.RNode, c *SecretOutputConfig) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = rn.Pipe(yaml.SetAnnotation(types.SecretOutputAnnotation, string(b)))
+	return err
+}
+
+// parseSealingKey returns the RSA public key of
+// a PEM encoded certificate or public key.
+func parseSealingKey(b []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	var key interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = cert.PublicKey
+	case "PUBLIC KEY":
+		var err error
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	case "RSA PUBLIC KEY":
+		var err error
+		if key, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %s", block.Type)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA public key")
+	}
+	return rsaKey, nil
+}
+
+// Emit rewrites the Secret in the configured format.
+func (c *SecretOutputConfig) Emit(rn *yaml.RNode) error {
+	switch c.Format {
+	case types.SecretOutputStringData:
+		return emitStringData(rn)
+	case types.SecretOutputSealed:
+		return c.seal(rn, rand.Reader)
+	}
+	return nil
+}
+
+// emitStringData moves the UTF-8 values of the Secret
+// from its data to its stringData, decoding them.
+func emitStringData(rn *yaml.RNode) error {
+	values, err := decodeSecretData(rn)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]string)
+	stringData := make(map[string]string)
+	for k, v := range values {
+		if utf8.Valid(v) {
+			stringData[k] = string(v)
+		} else {
+			data[k] = base64.StdEncoding.EncodeToString(v)
+		}
+	}
+	for _, field := range []string{yaml.DataField, "stringData"} {
+		if err = rn.PipeE(yaml.Clear(field)); err != nil {
+			return err
+		}
+	}
+	if err = setStringMap(rn, yaml.DataField, data); err != nil {
+		return err
+	}
+	return setStringMap(rn, "stringData", stringData)
+}
+
+// decodeSecretData returns the values of the Secret, those in
+// its stringData overriding those in its data, as the API does.
+func decodeSecretData(rn *yaml.RNode) (map[string][]byte, error) {
+	values := make(map[string][]byte)
+	for k, v := range rn.GetDataMap() {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("data key %s: %v", k, err)
+		}
+		values[k] = b
+	}
+	n, err := rn.Pipe(yaml.Lookup("stringData"))
+	if err != nil || n == nil {
+		return values, err
+	}
+	err = n.VisitFields(func(node *yaml.MapNode) error {
+		values[yaml.GetValue(node.Key)] = []byte(yaml.GetValue(node.Value))
+		return nil
+	})
+	return values, err
+}
+
+// setStringMap sets the field to the map, if not empty.
+func setStringMap(rn *yaml.RNode, field string, m map[string]string) error {
+	if len(m) == 0 {
+		return nil
+	}
+	mapNode, err := rn.Pipe(yaml.LookupCreate(yaml.MappingNode, field))
+	if err != nil {
+		return err
+	}
+	for _, k := range yaml.SortedMapKeys(m) {
+		v := yaml.NewStringRNode(m[k])
+		if strings.Contains(m[k], "\n") {
+			v.YNode().Style = yaml.LiteralStyle
+		}
+		if _, err = mapNode.Pipe(yaml.SetField(k, v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seal replaces the Secret with a SealedSecret, as kubeseal
+// would make it, its template holding the Secret without
+// its values.
+func (c *SecretOutputConfig) seal(rn *yaml.RNode, rnd io.Reader) error {
+	name, namespace := rn.GetName(), rn.GetNamespace()
+	var label []byte
+	switch c.Scope {
+	case types.SealingScopeStrict:
+		label = []byte(namespace + "/" + name)
+	case types.SealingScopeNamespaceWide:
+		label = []byte(namespace)
+	}
+	if namespace == "" && c.Scope != types.SealingScopeClusterWide {
+		return fmt.Errorf(
+			"sealing with the %s scope requires a namespace", c.Scope)
+	}
+	values, err := decodeSecretData(rn)
+	if err != nil {
+		return err
+	}
+	encrypted := make(map[string]string)
+	for k, v := range values {
+		ciphertext, err := hybridEncrypt(rnd, c.key, v, label)
+		if err != nil {
+			return err
+		}
+		encrypted[k] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	template := rn.Copy()
+	for _, field := range []string{
+		yaml.APIVersionField, yaml.KindField, yaml.DataField, "stringData"} {
+		if err = template.PipeE(yaml.Clear(field)); err != nil {
+			return err
+		}
+	}
+	sealed, err := makeBaseNode(sealedSecretKind, name, namespace)
+	if err != nil {
+		return err
+	}
+	sealed.SetApiVersion(sealedSecretAPIVersion)
+	if a, ok := sealingScopeAnnotations[c.Scope]; ok {
+		if err = sealed.PipeE(yaml.SetAnnotation(a, "true")); err != nil {
+			return err
+		}
+	}
+	spec, err := sealed.Pipe(yaml.LookupCreate(yaml.MappingNode, "spec"))
+	if err != nil {
+		return err
+	}
+	if err = setStringMap(spec, "encryptedData", encrypted); err != nil {
+		return err
+	}
+	if err = spec.PipeE(yaml.SetField("template", template)); err != nil {
+		return err
+	}
+	rn.SetYNode(sealed.YNode())
+	return nil
+}
+
+// hybridEncrypt encrypts the plaintext as the sealed secrets
+// controller expects: with a random AES-GCM session key, and a
+// zero nonce, preceded by the session key encrypted with RSA-OAEP
+// for the public key, with the scope label, and its length.
+func hybridEncrypt(
+	rnd io.Reader, key *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sealingSessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, 2, 2+len(rsaCiphertext)+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+	zeroNonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
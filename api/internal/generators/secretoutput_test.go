@@ -0,0 +1,318 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package generators_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/internal/generators"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const secretToEmit = `apiVersion: v1
+kind: Secret
+metadata:
+  name: db-7ch2h2c4d7
+  namespace: prod
+  labels:
+    app: db
+type: Opaque
+data:
+  password: c2VjcmV0
+  binary: /2hlbGxv
+`
+
+// makeSealingCertificate returns a key, and a self
+// signed certificate for it, as the controller has.
+func makeSealingCertificate(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// unseal decrypts a value of a SealedSecret,
+// as the sealed secrets controller does.
+func unseal(key *rsa.PrivateKey, value string, label []byte) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < 2 {
+		return nil, fmt.Errorf("too short")
+	}
+	n := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(
+		sha256.New(), rand.Reader, key, ciphertext[2:2+n], label)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+n:], nil)
+}
+
+func loadFrom(files map[string][]byte) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		if b, ok := files[path]; ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("no file %s", path)
+	}
+}
+
+func TestEmitSecretStringData(t *testing.T) {
+	c, err := MakeSecretOutputConfig(
+		&types.SecretOutput{Format: types.SecretOutputStringData}, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rn := yaml.MustParse(secretToEmit)
+	if !assert.NoError(t, c.Emit(rn)) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: db-7ch2h2c4d7
+  namespace: prod
+  labels:
+    app: db
+type: Opaque
+data:
+  binary: /2hlbGxv
+stringData:
+  password: secret
+`, rn.MustString())
+}
+
+// readSealingKey returns the test sealing key, and its
+// certificate, kubeseal sealed the files in testdata with.
+func readSealingKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	cert, err := os.ReadFile("testdata/sealedsecrets/cert.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := os.ReadFile("testdata/sealedsecrets/key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		t.Fatal("no PEM data in key.pem")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(*rsa.PrivateKey), cert
+}
+
+// unsealAll returns the unsealed values of the SealedSecret,
+// replacing their random ciphertexts to compare the rest.
+func unsealAll(
+	t *testing.T, key *rsa.PrivateKey, rn *yaml.RNode, label string) map[string]string {
+	t.Helper()
+	encrypted, err := rn.Pipe(yaml.Lookup("spec", "encryptedData"))
+	if !assert.NoError(t, err) || !assert.NotNil(t, encrypted) {
+		t.FailNow()
+	}
+	m, err := encrypted.Map()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	values := make(map[string]string)
+	for k, v := range m {
+		plaintext, err := unseal(key, v.(string), []byte(label))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		values[k] = string(plaintext)
+		_, err = encrypted.Pipe(yaml.SetField(k, yaml.NewStringRNode("...")))
+		assert.NoError(t, err)
+	}
+	return values
+}
+
+func TestEmitSealedSecret(t *testing.T) {
+	key, cert := readSealingKey(t)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"cert.pem": cert,
+		"key.pem":  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}),
+	}
+	testCases := map[string]struct {
+		output     types.SecretOutput
+		label      string
+		annotation string
+		// kubeseal sealed the Secret in this scope into the file.
+		sealedByKubeseal string
+	}{
+		"strict": {
+			output: types.SecretOutput{
+				Format: types.SecretOutputSealed, Certificate: "cert.pem"},
+			label:            "prod/db-7ch2h2c4d7",
+			sealedByKubeseal: "strict.yaml",
+		},
+		"namespace wide": {
+			output: types.SecretOutput{
+				Format: types.SecretOutputSealed, Certificate: "key.pem",
+				Scope: types.SealingScopeNamespaceWide},
+			label: "prod",
+			annotation: `
+  annotations:
+    sealedsecrets.bitnami.com/namespace-wide: 'true'`,
+			sealedByKubeseal: "namespace-wide.yaml",
+		},
+		"cluster wide": {
+			output: types.SecretOutput{
+				Format: types.SecretOutputSealed, Certificate: "cert.pem",
+				Scope: types.SealingScopeClusterWide},
+			annotation: `
+  annotations:
+    sealedsecrets.bitnami.com/cluster-wide: 'true'`,
+			sealedByKubeseal: "cluster-wide.yaml",
+		},
+	}
+	expected := map[string]string{"password": "secret", "binary": "\xffhello"}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// The unsealing is that of the controller: it
+			// unseals what kubeseal sealed.
+			byKubeseal, err := yaml.ReadFile(
+				"testdata/sealedsecrets/" + tc.sealedByKubeseal)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, expected, unsealAll(t, key, byKubeseal, tc.label))
+
+			c, err := MakeSecretOutputConfig(&tc.output, loadFrom(files))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			rn := yaml.MustParse(secretToEmit)
+			if !assert.NoError(t, c.Emit(rn)) {
+				t.FailNow()
+			}
+			assert.Equal(t, expected, unsealAll(t, key, rn, tc.label))
+			assert.Equal(t, byKubeseal.GetAnnotations(), rn.GetAnnotations())
+			assert.Equal(t, `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: db-7ch2h2c4d7
+  namespace: prod`+tc.annotation+`
+spec:
+  encryptedData:
+    binary: '...'
+    password: '...'
+  template:
+    metadata:
+      name: db-7ch2h2c4d7
+      namespace: prod
+      labels:
+        app: db
+    type: Opaque
+`, rn.MustString())
+		})
+	}
+}
+
+func TestSecretOutputErrors(t *testing.T) {
+	_, cert := makeSealingCertificate(t)
+	files := map[string][]byte{"cert.pem": cert, "bad.pem": []byte("nope")}
+	testCases := map[string]struct {
+		output types.SecretOutput
+		err    string
+	}{
+		"unknown format": {
+			output: types.SecretOutput{Format: "yaml"},
+			err:    "unknown secret output format 'yaml'; must be one of [data stringData sealed]",
+		},
+		"certificate without sealing": {
+			output: types.SecretOutput{
+				Format: types.SecretOutputStringData, Certificate: "cert.pem"},
+			err: "certificate and scope require the sealed secret output format",
+		},
+		"no certificate": {
+			output: types.SecretOutput{Format: types.SecretOutputSealed},
+			err:    "the sealed secret output format requires a certificate",
+		},
+		"unknown scope": {
+			output: types.SecretOutput{
+				Format: types.SecretOutputSealed, Certificate: "cert.pem",
+				Scope: "global"},
+			err: "unknown sealing scope 'global'; must be one of " +
+				"[strict namespace-wide cluster-wide]",
+		},
+		"missing certificate": {
+			output: types.SecretOutput{
+				Format: types.SecretOutputSealed, Certificate: "missing.pem"},
+			err: "no file missing.pem",
+		},
+		"bad certificate": {
+			output: types.SecretOutput{
+				Format: types.SecretOutputSealed, Certificate: "bad.pem"},
+			err: "certificate bad.pem: no PEM data found",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := MakeSecretOutputConfig(&tc.output, loadFrom(files))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+
+	c, err := MakeSecretOutputConfig(&types.SecretOutput{
+		Format: types.SecretOutputSealed, Certificate: "cert.pem"}, loadFrom(files))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rn := yaml.MustParse(`apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+data:
+  password: c2VjcmV0
+`)
+	assert.EqualError(t, c.Emit(rn),
+		"sealing with the strict scope requires a namespace")
+}
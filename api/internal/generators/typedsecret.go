@@ -0,0 +1,141 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package generators
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	dockerConfigJSONKey = ".dockerconfigjson"
+	tlsCertKey          = "tls.crt"
+	tlsPrivateKeyKey    = "tls.key"
+
+	// tlsExpiryWarningPeriod is how long before a TLS
+	// certificate expires that its Secret warns about it.
+	tlsExpiryWarningPeriod = 30 * 24 * time.Hour
+)
+
+// dockerConfigAuth is the entry of a registry
+// in the "auths" of a .dockerconfigjson.
+type dockerConfigAuth struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// makeDockerConfigJSON returns the pair holding
+// the .dockerconfigjson of the registries.
+func makeDockerConfigJSON(
+	ldr ifc.KvLoader, args *types.SecretArgs) (types.Pair, error) {
+	if args.Type != ifc.SecretTypeDockerConfigJSON {
+		return types.Pair{}, errors.Errorf(
+			"secret %s has registries, but isn't of type %s",
+			args.Name, ifc.SecretTypeDockerConfigJSON)
+	}
+	auths := make(map[string]dockerConfigAuth)
+	for i, r := range args.Registries {
+		if r.Registry == "" || r.Username == "" || r.PasswordFile == "" {
+			return types.Pair{}, errors.Errorf(
+				"registry #%d of secret %s must have a "+
+					"registry, username and passwordFile", i, args.Name)
+		}
+		if _, ok := auths[r.Registry]; ok {
+			return types.Pair{}, errors.Errorf(
+				"secret %s repeats the registry %s", args.Name, r.Registry)
+		}
+		b, err := loadFile(ldr, r.PasswordFile)
+		if err != nil {
+			return types.Pair{}, errors.WrapPrefix(err, "loading registry password", 0)
+		}
+		password := strings.TrimRight(string(b), "\r\n")
+		auths[r.Registry] = dockerConfigAuth{
+			Username: r.Username,
+			Password: password,
+			Email:    r.Email,
+			Auth: base64.StdEncoding.EncodeToString(
+				[]byte(r.Username + ":" + password)),
+		}
+	}
+	b, err := json.Marshal(map[string]interface{}{"auths": auths})
+	if err != nil {
+		return types.Pair{}, err
+	}
+	return types.Pair{Key: dockerConfigJSONKey, Value: string(b)}, nil
+}
+
+// validateTypedSecret checks the data of a Secret
+// against what its type requires.  The data of a
+// Secret to be merged into another may be partial.
+func validateTypedSecret(args *types.SecretArgs, m map[string]string) error {
+	partial := types.NewGenerationBehavior(args.Behavior) == types.BehaviorMerge
+	switch args.Type {
+	case ifc.SecretTypeDockerConfigJSON:
+		v, ok := m[dockerConfigJSONKey]
+		if !ok {
+			if partial {
+				return nil
+			}
+			return errors.Errorf(
+				"secret %s of type %s must have registries or the key %s",
+				args.Name, args.Type, dockerConfigJSONKey)
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &config); err != nil {
+			return errors.Errorf(
+				"the %s of secret %s isn't valid JSON: %v",
+				dockerConfigJSONKey, args.Name, err)
+		}
+	case ifc.SecretTypeTLS:
+		crt, hasCrt := m[tlsCertKey]
+		key, hasKey := m[tlsPrivateKeyKey]
+		if !hasCrt || !hasKey {
+			if partial {
+				return nil
+			}
+			return errors.Errorf(
+				"secret %s of type %s must have the keys %s and %s",
+				args.Name, args.Type, tlsCertKey, tlsPrivateKeyKey)
+		}
+		return validateTLS(args.Name, []byte(crt), []byte(key))
+	}
+	return nil
+}
+
+// validateTLS checks that the certificate and key
+// match, and warns if the certificate has expired
+// or is about to.
+func validateTLS(name string, crt, key []byte) error {
+	pair, err := tls.X509KeyPair(crt, key)
+	if err != nil {
+		return errors.Errorf(
+			"invalid TLS certificate and key in secret %s: %v", name, err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return errors.Errorf(
+			"invalid TLS certificate in secret %s: %v", name, err)
+	}
+	expiry := leaf.NotAfter.UTC().Format(time.RFC3339)
+	switch now := time.Now(); {
+	case now.After(leaf.NotAfter):
+		log.Printf(
+			"warning: the TLS certificate in secret %s expired at %s", name, expiry)
+	case now.Add(tlsExpiryWarningPeriod).After(leaf.NotAfter):
+		log.Printf(
+			"warning: the TLS certificate in secret %s expires at %s", name, expiry)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@
 package generators
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/go-errors/errors"
@@ -34,16 +35,47 @@ kind: %s
 	return rn, nil
 }
 
+// validateMergeKeys checks the keys to remove and rename
+// when merging with an existing resource.
+func validateMergeKeys(v ifc.Validator, args *types.GeneratorArgs) error {
+	if len(args.RemoveKeys) == 0 && len(args.RenameKeys) == 0 {
+		return nil
+	}
+	if types.NewGenerationBehavior(args.Behavior) != types.BehaviorMerge {
+		return errors.Errorf(
+			"%s: removeKeys and renameKeys require the merge behavior", args.Name)
+	}
+	targets := make(map[string]string)
+	for _, k := range yaml.SortedMapKeys(args.RenameKeys) {
+		newKey := args.RenameKeys[k]
+		if err := v.ErrIfInvalidKey(newKey); err != nil {
+			return err
+		}
+		if other, ok := targets[newKey]; ok {
+			return errors.Errorf(
+				"%s: keys '%s' and '%s' are both renamed to '%s'",
+				args.Name, other, k, newKey)
+		}
+		targets[newKey] = k
+	}
+	return nil
+}
+
 func makeValidatedDataMap(
 	ldr ifc.KvLoader, name string, sources types.KvPairSources) (map[string]string, error) {
 	pairs, err := ldr.Load(sources)
 	if err != nil {
 		return nil, errors.WrapPrefix(err, "loading KV pairs", 0)
 	}
+	return makeValidatedMap(ldr.Validator(), name, pairs)
+}
+
+func makeValidatedMap(
+	v ifc.Validator, name string, pairs []types.Pair) (map[string]string, error) {
 	knownKeys := make(map[string]string)
 	for _, p := range pairs {
 		// legal key: alphanumeric characters, '-', '_' or '.'
-		if err := ldr.Validator().ErrIfInvalidKey(p.Key); err != nil {
+		if err := v.ErrIfInvalidKey(p.Key); err != nil {
 			return nil, err
 		}
 		if _, ok := knownKeys[p.Key]; ok {
@@ -77,6 +109,39 @@ func copyLabelsAndAnnotations(
 	return nil
 }
 
+// loadFile reads a file the way file sources are read.
+func loadFile(ldr ifc.KvLoader, path string) ([]byte, error) {
+	pairs, err := ldr.Load(types.KvPairSources{FileSources: []string{path}})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(pairs[0].Value), nil
+}
+
+// setVolumeItems annotates the resource with the items of
+// a volume restoring the trees of the directory sources.
+func setVolumeItems(
+	rn *yaml.RNode, ldr ifc.KvLoader, sources []types.DirectorySource) error {
+	if len(sources) == 0 {
+		return nil
+	}
+	dl, ok := ldr.(ifc.DirectoryKvLoader)
+	if !ok {
+		return errors.Errorf(
+			"directory sources need a loader placing their files, not %T", ldr)
+	}
+	items, err := dl.DirectoryItems(sources)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	_, err = rn.Pipe(yaml.SetAnnotation(types.VolumeItemsAnnotation, string(b)))
+	return err
+}
+
 func setImmutable(
 	rn *yaml.RNode, opts *types.GeneratorOptions) error {
 	if opts == nil {
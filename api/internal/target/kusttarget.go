@@ -18,6 +18,7 @@ import (
 	"sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/yaml"
@@ -101,6 +102,62 @@ func loadKustFile(ldr ifc.Loader) ([]byte, error) {
 	}
 }
 
+// EditComponents drops the components at the given paths from the
+// components of the kustomization, then adds those at the given paths
+// which it doesn't list yet.  It's an error to drop a component the
+// kustomization doesn't list.
+func (kt *KustTarget) EditComponents(add, drop []string) error {
+	k := kt.kustomization
+	for _, path := range drop {
+		var kept []string
+		for _, c := range k.Components {
+			if !samePath(c, path) {
+				kept = append(kept, c)
+			}
+		}
+		var keptRefs []types.ComponentRef
+		for _, ref := range k.ParameterizedComponents {
+			if !samePath(ref.Path, path) {
+				keptRefs = append(keptRefs, ref)
+			}
+		}
+		if len(kept) == len(k.Components) &&
+			len(keptRefs) == len(k.ParameterizedComponents) {
+			return fmt.Errorf(
+				"can't drop component '%s'; the kustomization in '%s' doesn't list it",
+				path, kt.ldr.Root())
+		}
+		k.Components = kept
+		k.ParameterizedComponents = keptRefs
+	}
+	for _, path := range add {
+		if !kt.listsComponent(path) {
+			k.Components = append(k.Components, path)
+		}
+	}
+	return nil
+}
+
+// listsComponent tells whether the kustomization
+// lists the component at the path.
+func (kt *KustTarget) listsComponent(path string) bool {
+	for _, c := range kt.kustomization.Components {
+		if samePath(c, path) {
+			return true
+		}
+	}
+	for _, ref := range kt.kustomization.ParameterizedComponents {
+		if samePath(ref.Path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
 // MakeCustomizedResMap creates a fully customized ResMap
 // per the instructions contained in its kustomization instance.
 func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
@@ -116,6 +173,12 @@ func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 	// The following steps must be done last, not as part of
 	// the recursion implicit in AccumulateTarget.
 
+	// Checksums must be taken before hashes change names.
+	err = ra.AddContentChecksums(kt.rFactory.RF().Hasher())
+	if err != nil {
+		return nil, err
+	}
+
 	err = kt.addHashesToNames(ra)
 	if err != nil {
 		return nil, err
@@ -164,7 +227,13 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating resources")
 	}
-	ra, err = kt.accumulateComponents(ra, kt.kustomization.Components)
+	refs := make([]types.ComponentRef, 0, len(kt.kustomization.Components)+
+		len(kt.kustomization.ParameterizedComponents))
+	for _, path := range kt.kustomization.Components {
+		refs = append(refs, types.ComponentRef{Path: path})
+	}
+	refs = append(refs, kt.kustomization.ParameterizedComponents...)
+	ra, err = kt.accumulateComponents(ra, refs)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating components")
 	}
@@ -192,6 +261,14 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	if err != nil {
 		return nil, err
 	}
+	err = kt.addNamespace(ra)
+	if err != nil {
+		return nil, err
+	}
+	err = kt.markContentHashes(ra)
+	if err != nil {
+		return nil, err
+	}
 	err = kt.runTransformers(ra)
 	if err != nil {
 		return nil, err
@@ -208,6 +285,78 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	return ra, nil
 }
 
+// addNamespace generates the Namespace object of the
+// kustomization's namespace, ahead of the other resources,
+// if asked to by its namespaceOptions, unless the resources
+// hold it already; then it gets the labels and annotations
+// of the options instead.
+func (kt *KustTarget) addNamespace(ra *accumulator.ResAccumulator) error {
+	opts := kt.kustomization.NamespaceOptions
+	if opts == nil || !opts.Create {
+		return nil
+	}
+	name := kt.kustomization.Namespace
+	for _, r := range ra.ResMap().Resources() {
+		if r.GetKind() == "Namespace" && r.GetName() == name {
+			return setNamespaceMetadata(r, opts)
+		}
+	}
+	r := kt.rFactory.RF().FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+	})
+	if err := setNamespaceMetadata(r, opts); err != nil {
+		return err
+	}
+	return ra.PrependAll(kt.rFactory.FromResource(r))
+}
+
+func setNamespaceMetadata(
+	r *resource.Resource, opts *types.NamespaceOptions) error {
+	labels := r.GetLabels()
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+	if err := r.SetLabels(labels); err != nil {
+		return err
+	}
+	annotations := r.GetAnnotations()
+	for k, v := range opts.Annotations {
+		annotations[k] = v
+	}
+	return r.SetAnnotations(annotations)
+}
+
+// markContentHashes annotates the resources selected by
+// the kustomization's contentHashes with their mode.
+func (kt *KustTarget) markContentHashes(
+	ra *accumulator.ResAccumulator) error {
+	m := ra.ResMap()
+	for _, ch := range kt.kustomization.ContentHashes {
+		mode := ch.Mode
+		if mode == "" {
+			mode = types.ContentHashSuffix
+		}
+		for i := range ch.Targets {
+			resources, err := m.Select(ch.Targets[i])
+			if err != nil {
+				return errors.Wrap(err, "selecting content hash targets")
+			}
+			for _, r := range resources {
+				annotations := r.GetAnnotations()
+				annotations[types.ContentHashAnnotation] = string(mode)
+				if err = r.SetAnnotations(annotations); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (kt *KustTarget) runGenerators(
 	ra *accumulator.ResAccumulator) error {
 	var generators []resmap.Generator
@@ -341,7 +490,7 @@ func (kt *KustTarget) accumulateResources(
 				return nil, errors.Wrapf(
 					err, "accumulation err='%s'", errF.Error())
 			}
-			ra, err = kt.accumulateDirectory(ra, ldr, false)
+			ra, err = kt.accumulateDirectory(ra, ldr, false, nil)
 			if err != nil {
 				return nil, errors.Wrapf(
 					err, "accumulation err='%s'", errF.Error())
@@ -351,18 +500,18 @@ func (kt *KustTarget) accumulateResources(
 	return ra, nil
 }
 
-// accumulateResources fills the given resourceAccumulator
-// with resources read from the given list of paths.
+// accumulateComponents fills the given resourceAccumulator
+// with the given components.
 func (kt *KustTarget) accumulateComponents(
-	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
-	for _, path := range paths {
+	ra *accumulator.ResAccumulator, refs []types.ComponentRef) (*accumulator.ResAccumulator, error) {
+	for _, ref := range refs {
 		// Components always refer to directories
-		ldr, errL := kt.ldr.New(path)
+		ldr, errL := kt.ldr.New(ref.Path)
 		if errL != nil {
 			return nil, fmt.Errorf("loader.New %q", errL)
 		}
 		var errD error
-		ra, errD = kt.accumulateDirectory(ra, ldr, true)
+		ra, errD = kt.accumulateDirectory(ra, ldr, true, ref.Parameters)
 		if errD != nil {
 			return nil, fmt.Errorf("accumulateDirectory: %q", errD)
 		}
@@ -370,9 +519,20 @@ func (kt *KustTarget) accumulateComponents(
 	return ra, nil
 }
 
+// accumulateDirectory accumulates the kustomization or, if isComponent,
+// the component in the loader's root, given the values of its parameters.
 func (kt *KustTarget) accumulateDirectory(
-	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (*accumulator.ResAccumulator, error) {
+	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool,
+	parameters map[string]interface{}) (*accumulator.ResAccumulator, error) {
 	defer ldr.Cleanup()
+	if isComponent {
+		pLdr, err := parameterizedLoader(ldr, parameters)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "invalid parameters for component '%s'", ldr.Root())
+		}
+		ldr = pLdr
+	}
 	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
 	err := subKt.Load()
 	if err != nil {
@@ -413,7 +573,7 @@ func (kt *KustTarget) accumulateDirectory(
 		return nil, errors.Wrapf(
 			err, "recursed accumulation of path '%s'", ldr.Root())
 	}
-	err = ra.MergeAccumulator(subRa)
+	err = ra.MergeAccumulatorWithConflictPolicy(subRa, kt.kustomization.ConflictPolicy)
 	if err != nil {
 		return nil, errors.Wrapf(
 			err, "recursed merging from path '%s'", ldr.Root())
@@ -427,7 +587,7 @@ func (kt *KustTarget) accumulateFile(
 	if err != nil {
 		return errors.Wrapf(err, "accumulating resources from '%s'", path)
 	}
-	err = ra.AppendAll(resources)
+	err = ra.AppendAllWithConflictPolicy(resources, kt.kustomization.ConflictPolicy)
 	if err != nil {
 		return errors.Wrapf(err, "merging resources from '%s'", path)
 	}
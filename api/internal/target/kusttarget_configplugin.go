@@ -4,12 +4,17 @@
 package target
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strings"
 
+	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 // Functions dedicated to configuring the builtin
@@ -166,14 +171,16 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, _ *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
 		var c struct {
-			Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
-			Path   string          `json:"path,omitempty" yaml:"path,omitempty"`
-			JsonOp string          `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+			Target  *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Path    string          `json:"path,omitempty" yaml:"path,omitempty"`
+			JsonOp  string          `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+			Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 		}
 		for _, args := range kt.kustomization.PatchesJson6902 {
 			c.Target = args.Target
 			c.Path = args.Path
 			c.JsonOp = args.Patch
+			c.Options = args.Options
 			p := f()
 			err = kt.configureBuiltinPlugin(p, c, bpt)
 			if err != nil {
@@ -211,19 +218,27 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			Path    string          `json:"path,omitempty" yaml:"path,omitempty"`
 			Patch   string          `json:"patch,omitempty" yaml:"patch,omitempty"`
 			Target  *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Type    string          `json:"type,omitempty" yaml:"type,omitempty"`
 			Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 		}
-		for _, pc := range kt.kustomization.Patches {
-			c.Target = pc.Target
-			c.Patch = pc.Patch
-			c.Path = pc.Path
-			c.Options = pc.Options
-			p := f()
-			err = kt.configureBuiltinPlugin(p, c, bpt)
+		for _, args := range kt.kustomization.Patches {
+			patches, err := kt.expandPatch(args)
 			if err != nil {
 				return nil, err
 			}
-			result = append(result, p)
+			for _, pc := range patches {
+				c.Target = pc.Target
+				c.Type = pc.Type
+				c.Patch = pc.Patch
+				c.Path = pc.Path
+				c.Options = pc.Options
+				p := f()
+				err = kt.configureBuiltinPlugin(p, c, bpt)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, p)
+			}
 		}
 		return
 	},
@@ -365,3 +380,96 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return nil, fmt.Errorf("valueadd keyword not yet defined")
 	},
 }
+
+// expandPatch returns a patch for each file matching the patch's
+// path, which may be a glob or a directory, and for each document
+// within files holding more than one.  Each file is loaded once,
+// and its content passed along as the patch.
+func (kt *KustTarget) expandPatch(patch types.Patch) ([]types.Patch, error) {
+	if patch.Path == "" {
+		return []types.Patch{patch}, nil
+	}
+	files, err := kt.loadPatchFiles(patch.Path)
+	if err != nil {
+		return nil, err
+	}
+	var result []types.Patch
+	for _, content := range files {
+		docs, err := splitDocuments(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) < 2 {
+			docs = []string{string(content)}
+		}
+		for _, doc := range docs {
+			p := patch
+			p.Path = ""
+			p.Patch = doc
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// loadPatchFiles returns the contents of the files named by the
+// path.  If the loader can expand globs, the path may also be a
+// glob or a directory.
+func (kt *KustTarget) loadPatchFiles(path string) ([][]byte, error) {
+	gl, canGlob := kt.ldr.(ifc.GlobLoader)
+	var paths []string
+	if canGlob && strings.ContainsAny(path, "*?[") {
+		var err error
+		if paths, err = gl.Glob(path); err != nil {
+			return nil, err
+		}
+	} else {
+		content, err := kt.ldr.Load(path)
+		if err == nil {
+			return [][]byte{content}, nil
+		}
+		if !canGlob {
+			return nil, err
+		}
+		// it might be a directory
+		var errG error
+		if paths, errG = gl.Glob(path); errG != nil {
+			return nil, err
+		}
+	}
+	var result [][]byte
+	for _, p := range paths {
+		content, err := kt.ldr.Load(p)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, content)
+	}
+	return result, nil
+}
+
+// splitDocuments returns the YAML documents in the content,
+// skipping those holding nothing but comments.
+func splitDocuments(content []byte) ([]string, error) {
+	var result []string
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		doc := yaml.NewRNode(&node)
+		if yaml.IsMissingOrNull(doc) {
+			continue
+		}
+		s, err := doc.String()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+}
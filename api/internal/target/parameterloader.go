@@ -0,0 +1,209 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// parameterRef matches the references to parameters,
+// as written by types.ParameterReference.
+var parameterRef = regexp.MustCompile(`\$\{params\.([^}]*)\}`)
+
+// parameterLoader is a loader replacing the references to component
+// parameters in the files it loads with the parameters' values.
+// Values are substituted into the scalars of YAML files, so they
+// can't change the structure of the files, whatever they hold.
+// Loaders it makes for other roots, e.g. for the bases of the
+// component, don't replace anything.
+type parameterLoader struct {
+	ifc.Loader
+	// values are the formatted values of the parameters, by name.
+	values map[string]string
+	// tags are the YAML tags of the values of
+	// non-string parameters, by name.
+	tags map[string]string
+}
+
+var _ ifc.GlobLoader = &parameterLoader{}
+var _ ifc.TreeLoader = &parameterLoader{}
+
+// Load returns the file with the parameter values substituted.
+func (l *parameterLoader) Load(location string) ([]byte, error) {
+	content, err := l.Loader.Load(location)
+	if err != nil || !parameterRef.Match(content) {
+		return content, err
+	}
+	docs, err := decodeDocuments(content)
+	if err != nil || !hasStructure(docs) {
+		// not YAML holding fields, e.g. an env file
+		return l.substituteText(location, content)
+	}
+	var out bytes.Buffer
+	encoder := kyaml.NewEncoder(&out)
+	for _, doc := range docs {
+		l.substituteScalars(doc)
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Glob forwards to the wrapped loader, if it can expand globs.
+func (l *parameterLoader) Glob(pattern string) ([]string, error) {
+	gl, ok := l.Loader.(ifc.GlobLoader)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s needs a loader expanding globs, not %T", pattern, l.Loader)
+	}
+	return gl.Glob(pattern)
+}
+
+// Tree forwards to the wrapped loader, if it can list directory trees.
+func (l *parameterLoader) Tree(dir string) ([]string, error) {
+	tl, ok := l.Loader.(ifc.TreeLoader)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s needs a loader listing directories, not %T", dir, l.Loader)
+	}
+	return tl.Tree(dir)
+}
+
+// substitute returns the string with the references
+// to known parameters replaced by their values.
+func (l *parameterLoader) substitute(s string) string {
+	return parameterRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := l.values[parameterRef.FindStringSubmatch(ref)[1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// substituteText substitutes the values into a file which isn't
+// structured YAML.  As the lines of such files are usually entries,
+// it's an error to substitute a value holding a line break.
+func (l *parameterLoader) substituteText(
+	location string, content []byte) ([]byte, error) {
+	for _, m := range parameterRef.FindAllSubmatch(content, -1) {
+		if strings.ContainsAny(l.values[string(m[1])], "\r\n") {
+			return nil, fmt.Errorf(
+				"value of parameter '%s' can't hold a line break in %s",
+				m[1], location)
+		}
+	}
+	return []byte(l.substitute(string(content))), nil
+}
+
+// substituteScalars substitutes the values into the scalars under
+// the node.  A plain scalar which is nothing but a reference to a
+// non-string parameter takes the parameter's type; any other scalar
+// holding a reference becomes a string.
+func (l *parameterLoader) substituteScalars(node *kyaml.Node) {
+	if node.Kind != kyaml.ScalarNode {
+		for _, n := range node.Content {
+			l.substituteScalars(n)
+		}
+		return
+	}
+	if !parameterRef.MatchString(node.Value) {
+		return
+	}
+	if m := parameterRef.FindStringSubmatch(node.Value); m[0] == node.Value &&
+		node.Style == 0 && l.tags[m[1]] != "" {
+		node.Value = l.values[m[1]]
+		node.Tag = l.tags[m[1]]
+		return
+	}
+	node.Value = l.substitute(node.Value)
+	node.Tag = kyaml.NodeTagString
+}
+
+// decodeDocuments returns the YAML documents in the content.
+func decodeDocuments(content []byte) ([]*kyaml.Node, error) {
+	var result []*kyaml.Node
+	decoder := kyaml.NewDecoder(bytes.NewReader(content))
+	for {
+		doc := &kyaml.Node{}
+		err := decoder.Decode(doc)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, doc)
+	}
+}
+
+// hasStructure tells whether any of the documents holds a
+// mapping or a sequence, rather than being a scalar or empty.
+func hasStructure(docs []*kyaml.Node) bool {
+	for _, doc := range docs {
+		for _, n := range doc.Content {
+			if n.Kind == kyaml.MappingNode || n.Kind == kyaml.SequenceNode {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parameterizedLoader returns a loader for the component in the
+// root of ldr, which substitutes the values of its parameters.
+// It returns ldr itself if the component has no parameters.
+func parameterizedLoader(
+	ldr ifc.Loader, supplied map[string]interface{}) (ifc.Loader, error) {
+	declared, err := declaredParameters(ldr)
+	if err != nil {
+		return nil, err
+	}
+	if len(declared) == 0 && len(supplied) == 0 {
+		return ldr, nil
+	}
+	values, err := types.ResolveParameters(declared, supplied)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string)
+	for _, p := range declared {
+		switch p.Type {
+		case types.ParameterTypeInteger:
+			tags[p.Name] = kyaml.NodeTagInt
+		case types.ParameterTypeBoolean:
+			tags[p.Name] = kyaml.NodeTagBool
+		}
+	}
+	return &parameterLoader{Loader: ldr, values: values, tags: tags}, nil
+}
+
+// declaredParameters returns the parameters declared by the
+// kustomization file in the root of ldr.  The file is read leniently,
+// as its other fields may only be valid once parameters are substituted.
+func declaredParameters(ldr ifc.Loader) ([]types.ComponentParameter, error) {
+	content, err := loadKustFile(ldr)
+	if err != nil {
+		return nil, err
+	}
+	var k struct {
+		Parameters []types.ComponentParameter `json:"parameters"`
+	}
+	if err := yaml.Unmarshal(content, &k); err != nil {
+		return nil, err
+	}
+	return k.Parameters, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/schema"
+)
+
+const (
+	crdGroup = "apiextensions.k8s.io"
+	crdKind  = "CustomResourceDefinition"
+)
+
+// SchemaError lists the resource fields which don't conform
+// to their OpenAPI schema.
+type SchemaError struct {
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf(
+		"%d schema violation(s):\n  %s",
+		len(e.Violations), strings.Join(e.Violations, "\n  "))
+}
+
+// SchemaValidator checks resources against the active OpenAPI
+// schema, i.e. the builtin Kubernetes schema or a custom one set
+// with openapi.SetSchema, and against the schemas of any
+// CustomResourceDefinitions found among the resources.
+type SchemaValidator struct {
+	crdSchemas map[yaml.TypeMeta]*openapi.ResourceSchema
+}
+
+// NewSchemaValidator returns a SchemaValidator knowing the
+// schemas of the CustomResourceDefinitions in m.
+func NewSchemaValidator(m resmap.ResMap) (*SchemaValidator, error) {
+	v := &SchemaValidator{
+		crdSchemas: map[yaml.TypeMeta]*openapi.ResourceSchema{},
+	}
+	for _, r := range m.Resources() {
+		gvk := r.GetGvk()
+		if gvk.Group != crdGroup || gvk.Kind != crdKind {
+			continue
+		}
+		if err := v.addCrdSchemas(r); err != nil {
+			return nil, fmt.Errorf(
+				"unable to read schema of %s: %w", r.CurId(), err)
+		}
+	}
+	return v, nil
+}
+
+// Validate checks every resource in m, and returns a *SchemaError
+// listing all unknown fields, type mismatches and missing required
+// fields, or nil if there are none.
+// Resources without a known schema are skipped.
+func (v *SchemaValidator) Validate(m resmap.ResMap) error {
+	var violations []string
+	for _, r := range m.Resources() {
+		s := v.schemaFor(r)
+		if s == nil {
+			continue
+		}
+		for _, e := range schema.Validate(&r.RNode, s) {
+			violations = append(violations,
+				fmt.Sprintf("%s: %s", r.CurId(), e.Error()))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return &SchemaError{Violations: violations}
+}
+
+func (v *SchemaValidator) schemaFor(r *resource.Resource) *openapi.ResourceSchema {
+	t := yaml.TypeMeta{APIVersion: r.GetApiVersion(), Kind: r.GetKind()}
+	if s, found := v.crdSchemas[t]; found {
+		return s
+	}
+	return openapi.SchemaForResourceType(t)
+}
+
+// addCrdSchemas records the schema of each served version of the
+// CustomResourceDefinition.  Both apiextensions.k8s.io/v1, with a
+// schema per version, and v1beta1, with an optional top level
+// validation schema, are understood.
+func (v *SchemaValidator) addCrdSchemas(crd *resource.Resource) error {
+	group, err := requiredString(&crd.RNode, "spec", "group")
+	if err != nil {
+		return err
+	}
+	kind, err := requiredString(&crd.RNode, "spec", "names", "kind")
+	if err != nil {
+		return err
+	}
+	topLevel, err := crd.Pipe(yaml.Lookup("spec", "validation", "openAPIV3Schema"))
+	if err != nil {
+		return err
+	}
+	versions, err := crd.Pipe(yaml.Lookup("spec", "versions"))
+	if err != nil {
+		return err
+	}
+	if versions == nil {
+		// v1beta1 allows a single version field
+		version, err := requiredString(&crd.RNode, "spec", "version")
+		if err != nil {
+			return err
+		}
+		return v.addCrdSchema(group, version, kind, topLevel)
+	}
+	elements, err := versions.Elements()
+	if err != nil {
+		return err
+	}
+	for _, e := range elements {
+		version, err := requiredString(e, "name")
+		if err != nil {
+			return err
+		}
+		s, err := e.Pipe(yaml.Lookup("schema", "openAPIV3Schema"))
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			s = topLevel
+		}
+		if err := v.addCrdSchema(group, version, kind, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *SchemaValidator) addCrdSchema(
+	group, version, kind string, node *yaml.RNode) error {
+	if node == nil {
+		return nil
+	}
+	b, err := node.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var s spec.Schema
+	if err := s.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	t := yaml.TypeMeta{APIVersion: group + "/" + version, Kind: kind}
+	v.crdSchemas[t] = &openapi.ResourceSchema{Schema: &s}
+	return nil
+}
+
+func requiredString(node *yaml.RNode, path ...string) (string, error) {
+	f, err := node.Pipe(yaml.Lookup(path...))
+	if err != nil {
+		return "", err
+	}
+	if f == nil || f.YNode().Kind != yaml.ScalarNode || f.YNode().Value == "" {
+		return "", fmt.Errorf("missing %s", strings.Join(path, "."))
+	}
+	return f.YNode().Value, nil
+}
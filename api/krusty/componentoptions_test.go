@@ -0,0 +1,111 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeOptionalComponents(th kusttest_test.Harness) {
+	th.WriteK("prod", `
+resources:
+- service.yaml
+components:
+- ../monitoring
+`)
+	th.WriteF("prod/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteC("monitoring", `
+commonAnnotations:
+  monitored: "true"
+`)
+	th.WriteC("debug", `
+commonAnnotations:
+  debug: "true"
+`)
+}
+
+func TestComponentOptions(t *testing.T) {
+	testCases := map[string]struct {
+		components        []string
+		withoutComponents []string
+		expectedOutput    string
+	}{
+		"add": {
+			components: []string{"../debug"},
+			expectedOutput: `
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    debug: "true"
+    monitored: "true"
+  name: web
+`,
+		},
+		"drop": {
+			withoutComponents: []string{"../monitoring"},
+			expectedOutput: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`,
+		},
+		"add and drop": {
+			components:        []string{"../debug"},
+			withoutComponents: []string{"./../monitoring/"},
+			expectedOutput: `
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    debug: "true"
+  name: web
+`,
+		},
+		"add listed component": {
+			components: []string{"../monitoring"},
+			expectedOutput: `
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    monitored: "true"
+  name: web
+`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeOptionalComponents(th)
+			opts := th.MakeDefaultOptions()
+			opts.Components = tc.components
+			opts.WithoutComponents = tc.withoutComponents
+			m := th.Run("prod", opts)
+			th.AssertActualEqualsExpected(m, tc.expectedOutput)
+		})
+	}
+}
+
+func TestComponentOptionsDropUnlisted(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOptionalComponents(th)
+	opts := th.MakeDefaultOptions()
+	opts.WithoutComponents = []string{"../debug"}
+	err := th.RunWithErr("prod", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"can't drop component '../debug'; the kustomization in") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
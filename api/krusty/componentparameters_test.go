@@ -0,0 +1,364 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeParameterizedComponent(th kusttest_test.Harness) {
+	th.WriteC("comp", `
+parameters:
+- name: env
+  description: environment of the deployment
+- name: replicas
+  type: integer
+  default: 2
+- name: debug
+  type: boolean
+  default: false
+nameSuffix: -${params.env}
+replicas:
+- name: storefront
+  count: ${params.replicas}
+patches:
+- path: patch.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - debug=${params.debug}
+`)
+	th.WriteF("comp/patch.yaml", `
+apiVersion: v1
+kind: Deployment
+metadata:
+  name: storefront
+  labels:
+    env: ${params.env}
+`)
+}
+
+func TestComponentParameters(t *testing.T) {
+	testCases := map[string]struct {
+		input          []FileGen
+		expectedOutput string
+	}{
+		"defaults": {
+			input: []FileGen{writeTestBase, writeParameterizedComponent,
+				writeK("prod", `
+resources:
+- ../base
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    env: prod
+`),
+			},
+			expectedOutput: `
+apiVersion: v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: storefront-prod
+spec:
+  replicas: 2
+---
+apiVersion: v1
+data:
+  otherValue: green
+  testValue: purple
+kind: ConfigMap
+metadata:
+  name: my-configmap-prod-9cd648hm8f
+---
+apiVersion: v1
+data:
+  debug: "false"
+kind: ConfigMap
+metadata:
+  name: settings-prod-dm8hck6684
+`,
+		},
+		"supplied values": {
+			input: []FileGen{writeTestBase, writeParameterizedComponent,
+				writeK("prod", `
+resources:
+- ../base
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    env: dev
+    replicas: 5
+    debug: true
+`),
+			},
+			expectedOutput: `
+apiVersion: v1
+kind: Deployment
+metadata:
+  labels:
+    env: dev
+  name: storefront-dev
+spec:
+  replicas: 5
+---
+apiVersion: v1
+data:
+  otherValue: green
+  testValue: purple
+kind: ConfigMap
+metadata:
+  name: my-configmap-dev-9cd648hm8f
+---
+apiVersion: v1
+data:
+  debug: "true"
+kind: ConfigMap
+metadata:
+  name: settings-dev-tg6kd48m92
+`,
+		},
+		"same component with different values": {
+			input: []FileGen{
+				writeC("comp", `
+parameters:
+- name: name
+resources:
+- service.yaml
+`),
+				writeF("comp/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: ${params.name}
+`),
+				writeK("prod", `
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    name: web
+- path: ../comp
+  parameters:
+    name: api
+`),
+			},
+			expectedOutput: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+`,
+		},
+		"values holding yaml syntax": {
+			input: []FileGen{
+				writeC("comp", `
+parameters:
+- name: owner
+- name: count
+resources:
+- configmap.yaml
+`),
+				writeF("comp/configmap.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: owners
+data:
+  owner: ${params.owner}
+  count: ${params.count}
+  summary: "${params.count} owned by ${params.owner}"
+`),
+				writeK("prod", `
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    owner: "alice\n  admin: 'true'\nkind: Secret"
+    count: "3"
+`),
+			},
+			expectedOutput: `
+apiVersion: v1
+data:
+  count: "3"
+  owner: |-
+    alice
+      admin: 'true'
+    kind: Secret
+  summary: |-
+    3 owned by alice
+      admin: 'true'
+    kind: Secret
+kind: ConfigMap
+metadata:
+  name: owners
+`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			for _, f := range tc.input {
+				f(th)
+			}
+			m := th.Run("prod", th.MakeDefaultOptions())
+			th.AssertActualEqualsExpected(m, tc.expectedOutput)
+		})
+	}
+}
+
+func TestComponentParameterErrors(t *testing.T) {
+	testCases := map[string]struct {
+		components    string
+		expectedError string
+	}{
+		"missing value": {
+			components: `
+components:
+- ../comp
+`,
+			expectedError: "missing value for parameter 'env'",
+		},
+		"unknown parameter": {
+			components: `
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    env: prod
+    color: blue
+`,
+			expectedError: "unknown parameter(s) [color]",
+		},
+		"wrong type": {
+			components: `
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    env: prod
+    replicas: many
+`,
+			expectedError: "value many of parameter 'replicas' isn't of type integer",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeTestBase(th)
+			writeParameterizedComponent(th)
+			th.WriteK("prod", `
+resources:
+- ../base
+`+tc.components)
+			err := th.RunWithErr("prod", th.MakeDefaultOptions())
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+
+	t.Run("line break in an env file", func(t *testing.T) {
+		th := kusttest_test.MakeHarness(t)
+		th.WriteC("comp", `
+parameters:
+- name: owner
+configMapGenerator:
+- name: owners
+  envs:
+  - owners.env
+`)
+		th.WriteF("comp/owners.env", `
+owner=${params.owner}
+`)
+		th.WriteK("prod", `
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    owner: "alice\nadmin=true"
+`)
+		err := th.RunWithErr("prod", th.MakeDefaultOptions())
+		if err == nil || !strings.Contains(err.Error(),
+			"value of parameter 'owner' can't hold a line break in") {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("parameters of a kustomization", func(t *testing.T) {
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK("prod", `
+parameters:
+- name: env
+`)
+		err := th.RunWithErr("prod", th.MakeDefaultOptions())
+		if err == nil || !strings.Contains(
+			err.Error(), "only a Component can declare parameters") {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+}
+
+func TestComponentParametersWithGlobsAndDirectories(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteC("comp", `
+parameters:
+- name: host
+patches:
+- path: patches/*.yaml
+configMapGenerator:
+- name: nginx
+  directories:
+  - path: conf
+`)
+	th.WriteF("comp/patches/labels.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    host: ${params.host}
+`)
+	th.WriteF("comp/conf/nginx.conf", "server_name ${params.host};\n")
+	th.WriteF("prod/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteK("prod", `
+resources:
+- service.yaml
+parameterizedComponents:
+- path: ../comp
+  parameters:
+    host: example.com
+`)
+	m := th.Run("prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    host: example.com
+  name: web
+---
+apiVersion: v1
+data:
+  nginx.conf: |
+    server_name example.com;
+kind: ConfigMap
+metadata:
+  annotations:
+    kustomize.config.k8s.io/volume-items: '[{"key":"nginx.conf","path":"nginx.conf"}]'
+  name: nginx-cgbt24t7gm
+`)
+}
@@ -4,6 +4,9 @@
 package krusty_test
 
 import (
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -118,6 +121,118 @@ metadata:
 `)
 }
 
+func TestGeneratorFromStructuredFiles(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+configMapGenerator:
+- name: app
+  structured:
+  - path: config.yaml
+    keySeparator: _
+  - path: logging.toml
+    subtrees:
+    - path: loggers
+      key: loggers.toml
+`)
+	th.WriteF("config.yaml", `
+db:
+  host: localhost
+  port: 5432
+`)
+	th.WriteF("logging.toml", `
+level = "info"
+[loggers.http]
+level = "debug"
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `apiVersion: v1
+data:
+  db_host: localhost
+  db_port: "5432"
+  loggers.toml: |
+    [http]
+      level = "debug"
+kind: ConfigMap
+metadata:
+  name: app-47bf6h84t2
+`)
+}
+
+func TestGeneratorFromDotenvFiles(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+configMapGenerator:
+- name: app
+  envs:
+  - app.env
+  envFormat: dotenv
+  expandEnvVars: true
+`)
+	th.WriteF("app.env", `
+# Connection settings.
+export HOST=db
+PORT=5432  # the default
+URL="postgres://${HOST}:${PORT}/app?sslmode=${SSLMODE:-disable}"
+GREETING='Hello, $USER'
+CERT="-----BEGIN CERTIFICATE-----
+MIIB
+-----END CERTIFICATE-----"
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  CERT: |-
+    -----BEGIN CERTIFICATE-----
+    MIIB
+    -----END CERTIFICATE-----
+  GREETING: Hello, $USER
+  HOST: db
+  PORT: "5432"
+  URL: postgres://db:5432/app?sslmode=disable
+kind: ConfigMap
+metadata:
+  name: app-t59b87bt92
+`)
+}
+
+func TestGeneratorFromExternalSources(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("needs echo")
+	}
+	os.Setenv("KUSTOMIZE_TEST_BUILD_ID", "1234")
+	defer os.Unsetenv("KUSTOMIZE_TEST_BUILD_ID")
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+configMapGenerator:
+- name: build
+  envVars:
+  - id=KUSTOMIZE_TEST_BUILD_ID
+  commands:
+  - key: version
+    command: [echo, v1.2.3]
+    timeout: 10s
+    trimSpace: true
+`)
+	opts := th.MakeDefaultOptions()
+	err := th.RunWithErr(".", opts)
+	if !strings.Contains(err.Error(),
+		"env var sources [id=KUSTOMIZE_TEST_BUILD_ID] require --enable-external-sources") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts.PluginConfig.ExternalSourcesConfig.Enabled = true
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  id: "1234"
+  version: v1.2.3
+kind: ConfigMap
+metadata:
+  name: build-kd84mkb84g
+`)
+}
+
 // Generate a Secret and a ConfigMap from the same data
 // to compare the result.
 func TestGeneratorBasics(t *testing.T) {
@@ -549,3 +664,142 @@ metadata:
   name: testing-tt4769fb52
 `)
 }
+
+func TestSecretGeneratorFromRegistries(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("ghcr.token", "token\n")
+	th.WriteK(".", `
+secretGenerator:
+- name: regcred
+  type: kubernetes.io/dockerconfigjson
+  registries:
+  - registry: ghcr.io
+    username: bot
+    passwordFile: ghcr.token
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  .dockerconfigjson: |
+    eyJhdXRocyI6eyJnaGNyLmlvIjp7InVzZXJuYW1lIjoiYm90IiwicGFzc3dvcmQiOiJ0b2
+    tlbiIsImF1dGgiOiJZbTkwT25SdmEyVnUifX19
+kind: Secret
+metadata:
+  name: regcred-g5tk8fmgb5
+type: kubernetes.io/dockerconfigjson
+`)
+}
+
+func TestGeneratorMergeRemovingAndRenamingKeys(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+configMapGenerator:
+- name: app
+  literals:
+  - LOG_LEVEL=debug
+  - DEBUG_PORT=5005
+  - DB_HOST=db
+secretGenerator:
+- name: creds
+  literals:
+  - user=admin
+  - pass=secret
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+configMapGenerator:
+- name: app
+  behavior: merge
+  removeKeys:
+  - DEBUG_PORT
+  renameKeys:
+    DB_HOST: DATABASE_HOST
+  literals:
+  - LOG_LEVEL=info
+secretGenerator:
+- name: creds
+  behavior: merge
+  renameKeys:
+    pass: password
+`)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  DATABASE_HOST: db
+  LOG_LEVEL: info
+kind: ConfigMap
+metadata:
+  name: app-m7mdkbbd88
+---
+apiVersion: v1
+data:
+  password: c2VjcmV0
+  user: YWRtaW4=
+kind: Secret
+metadata:
+  name: creds-g4g252bmmt
+type: Opaque
+`)
+
+	th.WriteK("overlay", `
+resources:
+- ../base
+configMapGenerator:
+- name: app
+  behavior: merge
+  removeKeys:
+  - DEBUG
+`)
+	err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+	if !strings.Contains(err.Error(),
+		"cannot remove key 'DEBUG' of ~G_v1_ConfigMap|~X|app; it doesn't exist") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	th.WriteK("overlay", `
+resources:
+- ../base
+configMapGenerator:
+- name: app
+  behavior: replace
+  removeKeys:
+  - DEBUG_PORT
+`)
+	err = th.RunWithErr("overlay", th.MakeDefaultOptions())
+	if !strings.Contains(err.Error(),
+		"app: removeKeys and renameKeys require the merge behavior") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGeneratorFromDirectoryTree(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+configMapGenerator:
+- name: nginx
+  directories:
+  - path: nginx
+    exclude:
+    - '*.bak'
+`)
+	th.WriteF("nginx/nginx.conf", "include conf.d/*.conf;\n")
+	th.WriteF("nginx/conf.d/default.conf", "server {}\n")
+	th.WriteF("nginx/conf.d/default.conf.bak", "server { old }\n")
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  conf.d_default.conf: |
+    server {}
+  nginx.conf: |
+    include conf.d/*.conf;
+kind: ConfigMap
+metadata:
+  annotations:
+    kustomize.config.k8s.io/volume-items: '[{"key":"conf.d_default.conf","path":"conf.d/default.conf"},{"key":"nginx.conf","path":"nginx.conf"}]'
+  name: nginx-mckd4bhb7b
+`)
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// writeConflictingBases writes two bases holding the
+// same Namespace, which differ unless sameNamespace.
+func writeConflictingBases(th kusttest_test.Harness, sameNamespace bool) {
+	label := "b"
+	if sameNamespace {
+		label = "a"
+	}
+	th.WriteK("a", `
+resources:
+- namespace.yaml
+- service.yaml
+`)
+	th.WriteF("a/namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shared
+  labels:
+    owner: a
+`)
+	th.WriteF("a/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+`)
+	th.WriteK("b", `
+resources:
+- namespace.yaml
+- service.yaml
+`)
+	th.WriteF("b/namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shared
+  labels:
+    owner: `+label+`
+`)
+	th.WriteF("b/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+`)
+}
+
+func TestConflictPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		policy        string
+		sameNamespace bool
+		expectedOwner string
+		expectedError string
+	}{
+		"default": {
+			expectedError: `may not add resource with an already registered id: ~G_v1_Namespace|~X|shared; the copies differ:
+--- first
++++ last
+@@ -2,6 +2,6 @@
+ kind: Namespace
+ metadata:
+   labels:
+-    owner: a
++    owner: b
+   name: shared
+`,
+		},
+		"error on equal copies": {
+			policy:        "error",
+			sameNamespace: true,
+			expectedError: "may not add resource with an already registered id: ~G_v1_Namespace|~X|shared",
+		},
+		"keep-first": {
+			policy:        "keep-first",
+			expectedOwner: "a",
+		},
+		"keep-last": {
+			policy:        "keep-last",
+			expectedOwner: "b",
+		},
+		"merge-if-equal": {
+			policy:        "merge-if-equal",
+			sameNamespace: true,
+			expectedOwner: "a",
+		},
+		"merge-if-equal on differing copies": {
+			policy:        "merge-if-equal",
+			expectedError: "can't merge the differing copies of ~G_v1_Namespace|~X|shared",
+		},
+		"unknown policy": {
+			policy:        "keep-both",
+			expectedError: "unknown conflict policy 'keep-both'",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeConflictingBases(th, tc.sameNamespace)
+			k := `
+resources:
+- ../a
+- ../b
+`
+			if tc.policy != "" {
+				k += "conflictPolicy: " + tc.policy + "\n"
+			}
+			th.WriteK("prod", k)
+			if tc.expectedError != "" {
+				err := th.RunWithErr("prod", th.MakeDefaultOptions())
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			m := th.Run("prod", th.MakeDefaultOptions())
+			th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    owner: `+tc.expectedOwner+`
+  name: shared
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+`)
+		})
+	}
+}
+
+func TestConflictPolicyAnnotation(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConflictingBases(th, false)
+	th.WriteF("b/namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shared
+  labels:
+    owner: b
+  annotations:
+    kustomize.config.k8s.io/conflict-policy: keep-last
+`)
+	// the annotation overrides the kustomization's policy
+	th.WriteK("prod", `
+resources:
+- ../a
+- ../b
+conflictPolicy: merge-if-equal
+`)
+	m := th.Run("prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    owner: b
+  name: shared
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+`)
+}
+
+func TestConflictPolicyAnnotationsDisagree(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConflictingBases(th, true)
+	th.WriteF("a/namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shared
+  labels:
+    owner: a
+  annotations:
+    kustomize.config.k8s.io/conflict-policy: keep-first
+`)
+	th.WriteF("b/namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shared
+  labels:
+    owner: a
+  annotations:
+    kustomize.config.k8s.io/conflict-policy: keep-last
+`)
+	th.WriteK("prod", `
+resources:
+- ../a
+- ../b
+`)
+	err := th.RunWithErr("prod", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"the copies of ~G_v1_Namespace|~X|shared have the conflicting policies 'keep-first' and 'keep-last'") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
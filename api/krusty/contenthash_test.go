@@ -0,0 +1,277 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeContentHashBase(th kusttest_test.Harness) {
+	th.WriteF("base/config.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  level: debug
+`)
+	th.WriteF("base/database.yaml", `
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+spec:
+  size: 10Gi
+`)
+	th.WriteF("base/workloads.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: app-config
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          volumes:
+          - name: config
+            configMap:
+              name: app-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: other
+spec:
+  template:
+    spec:
+      containers:
+      - name: other
+        image: other
+`)
+}
+
+func TestContentHashSuffix(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeContentHashBase(th)
+	th.WriteK("base", `
+resources:
+- config.yaml
+- database.yaml
+- workloads.yaml
+contentHashes:
+- targets:
+  - kind: Database
+`)
+	th.WriteF("base/config.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  annotations:
+    kustomize.config.k8s.io/content-hash: suffix
+data:
+  level: debug
+`)
+	th.WriteK("prod", `
+namePrefix: prod-
+resources:
+- ../base
+`)
+	m := th.Run("prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  level: debug
+kind: ConfigMap
+metadata:
+  name: prod-app-config-ck5fk26hc4
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: prod-db-2tdm99ckhg
+spec:
+  size: 10Gi
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-app
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: prod-app-config-ck5fk26hc4
+        image: app
+        name: app
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: prod-report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          volumes:
+          - configMap:
+              name: prod-app-config-ck5fk26hc4
+            name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-other
+spec:
+  template:
+    spec:
+      containers:
+      - image: other
+        name: other
+`)
+}
+
+func TestContentHashSuffixIgnoresFormatting(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeContentHashBase(th)
+	th.WriteK("base", `
+resources:
+- database.yaml
+contentHashes:
+- targets:
+  - kind: Database
+`)
+	m := th.Run("base", th.MakeDefaultOptions())
+	assert.Equal(t, "db-ck8m8g85ct", m.Resources()[0].GetName())
+
+	// Comments, field order and indentation aren't content.
+	th.WriteF("base/database.yaml", `
+# a comment
+
+spec:
+    size: "10Gi"
+kind: Database
+metadata: {name: db}
+apiVersion: example.com/v1
+`)
+	m = th.Run("base", th.MakeDefaultOptions())
+	assert.Equal(t, "db-ck8m8g85ct", m.Resources()[0].GetName())
+}
+
+func TestContentHashChecksum(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeContentHashBase(th)
+	th.WriteK("base", `
+resources:
+- config.yaml
+- workloads.yaml
+contentHashes:
+- mode: checksum
+  targets:
+  - kind: ConfigMap
+    name: app-config
+`)
+	th.WriteK("prod", `
+namePrefix: prod-
+resources:
+- ../base
+`)
+	m := th.Run("prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  level: debug
+kind: ConfigMap
+metadata:
+  name: prod-app-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-app
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/prod-app-config: ck5fk26hc4
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: prod-app-config
+        image: app
+        name: app
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: prod-report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        metadata:
+          annotations:
+            checksum/prod-app-config: ck5fk26hc4
+        spec:
+          volumes:
+          - configMap:
+              name: prod-app-config
+            name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-other
+spec:
+  template:
+    spec:
+      containers:
+      - image: other
+        name: other
+`)
+}
+
+func TestContentHashUnknownMode(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- config.yaml
+`)
+	th.WriteF("config.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  annotations:
+    kustomize.config.k8s.io/content-hash: prefix
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil || !strings.Contains(err.Error(),
+		"unknown content hash mode 'prefix'; must be one of [suffix checksum]") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
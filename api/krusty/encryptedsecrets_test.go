@@ -0,0 +1,49 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestGeneratorFromEncryptedEnvFile(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	// Encrypted by the sops and age commands; see its README.md.
+	for _, f := range []string{"app.env", "age.env", "keys.txt"} {
+		content, err := ioutil.ReadFile(filepath.Join("../kv/testdata/sops", f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		th.WriteF(filepath.Join("app", f), string(content))
+	}
+	th.WriteK("app", `
+secretGenerator:
+- name: db
+  literals:
+  - HOST=localhost
+  encrypted:
+    envs:
+    - app.env
+    - age.env
+    ageKeys:
+      file: keys.txt
+`)
+	m := th.Run("app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  HOST: bG9jYWxob3N0
+  PASSWORD: czNjcmV0
+  TOKEN: YWJj
+  USER: YWRtaW4=
+kind: Secret
+metadata:
+  name: db-t74t879m2k
+type: Opaque
+`)
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeExpressionResources(th kusttest_test.Harness) {
+	th.WriteF("deployments.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: single
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: replicated
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)
+}
+
+func TestPatchTargetExpression(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeExpressionResources(th)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployments.yaml
+patches:
+- target:
+    expression: object.kind == "Deployment" && object.spec.replicas > 1
+  patch: |-
+    - op: add
+      path: /spec/strategy
+      value:
+        type: RollingUpdate
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: single
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: replicated
+spec:
+  replicas: 3
+  strategy:
+    type: RollingUpdate
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)
+}
+
+func TestValidatorExpression(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeExpressionResources(th)
+	th.WriteF("policy.yaml", `
+apiVersion: builtin
+kind: PolicyValidator
+metadata:
+  name: policy
+rules:
+- name: ha
+  target:
+    kind: Deployment
+  expressions:
+  - expression: object.spec.replicas >= 2
+    message: must have at least two replicas
+`)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployments.yaml
+validators:
+- policy.yaml
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), `1 policy violation(s):
+  rule ha: apps_v1_Deployment|~X|single: must have at least two replicas`)
+}
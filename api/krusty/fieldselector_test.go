@@ -0,0 +1,105 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestPatchTargetFieldSelector(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      serviceAccountName: default
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    spec:
+      serviceAccountName: worker
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: LoadBalancer
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: internal
+spec:
+  type: ClusterIP
+`)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- resources.yaml
+patches:
+- target:
+    kind: Deployment
+    fieldSelector: spec.template.spec.serviceAccountName=default
+  patch: |-
+    - op: add
+      path: /spec/template/spec/automountServiceAccountToken
+      value: false
+- target:
+    kind: Service
+    fieldSelector: spec.type~LoadBalancer|NodePort,!spec.loadBalancerSourceRanges
+  patch: |-
+    - op: add
+      path: /spec/loadBalancerSourceRanges
+      value: [10.0.0.0/8]
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      automountServiceAccountToken: false
+      serviceAccountName: default
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    spec:
+      serviceAccountName: worker
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  loadBalancerSourceRanges:
+  - 10.0.0.0/8
+  type: LoadBalancer
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: internal
+spec:
+  type: ClusterIP
+`)
+}
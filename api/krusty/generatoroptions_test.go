@@ -4,6 +4,7 @@
 package krusty_test
 
 import (
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -94,3 +95,127 @@ metadata:
   name: shouldHaveHash-c9867f8446
 `)
 }
+
+func TestGeneratorOptionsHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+generatorOptions:
+  hashLength: 6
+configMapGenerator:
+- name: short
+  literals:
+  - fruit=apple
+- name: salted
+  literals:
+  - fruit=apple
+  options:
+    hashLength: 8
+    hashSalt: pepper
+secretGenerator:
+- name: default
+  literals:
+  - fruit=apple
+  options:
+    hashLength: 10
+resources:
+- pod.yaml
+`)
+	th.WriteF("pod.yaml", `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+  - name: app
+    image: app
+    envFrom:
+    - configMapRef:
+        name: short
+    - configMapRef:
+        name: salted
+    - secretRef:
+        name: default
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+  - envFrom:
+    - configMapRef:
+        name: short-c9867f
+    - configMapRef:
+        name: salted-99f4g6hh
+    - secretRef:
+        name: default-6629fg2k2h
+    image: app
+    name: app
+---
+apiVersion: v1
+data:
+  fruit: apple
+kind: ConfigMap
+metadata:
+  name: short-c9867f
+---
+apiVersion: v1
+data:
+  fruit: apple
+kind: ConfigMap
+metadata:
+  name: salted-99f4g6hh
+---
+apiVersion: v1
+data:
+  fruit: YXBwbGU=
+kind: Secret
+metadata:
+  name: default-6629fg2k2h
+type: Opaque
+`)
+}
+
+func TestGeneratorOptionsHashLengthOutOfBounds(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+generatorOptions:
+  hashLength: 3
+configMapGenerator:
+- name: short
+  literals:
+  - fruit=apple
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "hashLength 3 must be between 5 and 64") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGeneratorOptionsHashLengthOutOfBoundsWithoutHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+generatorOptions:
+  disableNameSuffixHash: true
+  hashLength: 3
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "hashLength 3 must be between 5 and 64") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
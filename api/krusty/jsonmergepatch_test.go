@@ -0,0 +1,172 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestJsonMergePatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("crontab.yaml", `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+spec:
+  cronSpec: "* * * * */5"
+  schedule:
+    timeZone: UTC
+    paused: true
+  args:
+  - --full
+  - --verify
+---
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: cleanup
+spec:
+  cronSpec: "0 * * * *"
+`)
+	th.WriteF("patch.yaml", `
+spec:
+  schedule:
+    paused: null
+  args:
+  - --incremental
+`)
+	th.WriteK(".", `
+resources:
+- crontab.yaml
+patches:
+- path: patch.yaml
+  type: merge
+  target:
+    kind: CronTab
+    name: backup
+- type: merge
+  target:
+    kind: CronTab
+  patch: |-
+    {"metadata": {"labels": {"team": "ops"}}}
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  labels:
+    team: ops
+  name: backup
+spec:
+  args:
+  - --incremental
+  cronSpec: '* * * * */5'
+  schedule:
+    timeZone: UTC
+---
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  labels:
+    team: ops
+  name: cleanup
+spec:
+  cronSpec: 0 * * * *
+`)
+}
+
+func TestJsonMergePatchErrors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("crontab.yaml", `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+`)
+	th.WriteK(".", `
+resources:
+- crontab.yaml
+patches:
+- type: merge
+  patch: |-
+    spec:
+      paused: true
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must specify a target for patch")
+	}
+
+	th.WriteK(".", `
+resources:
+- crontab.yaml
+patches:
+- type: merge
+  target:
+    kind: CronTab
+  patch: |-
+    - op: add
+      path: /spec/paused
+      value: true
+`)
+	err = th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to parse merge patch object")
+	}
+
+	th.WriteK(".", `
+resources:
+- crontab.yaml
+patches:
+- type: strategic
+  target:
+    kind: CronTab
+  patch: |-
+    spec:
+      paused: true
+`)
+	err = th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			`unknown patch type "strategic"; must be one of strategicMerge, json6902 or merge`)
+	}
+}
+
+func TestExplicitJson6902PatchType(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("crontab.yaml", `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+spec:
+  paused: false
+`)
+	th.WriteK(".", `
+resources:
+- crontab.yaml
+patches:
+- type: json6902
+  target:
+    kind: CronTab
+  patch: |-
+    - op: replace
+      path: /spec/paused
+      value: true
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+spec:
+  paused: true
+`)
+}
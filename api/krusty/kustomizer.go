@@ -5,19 +5,25 @@ package krusty
 
 import (
 	"fmt"
+	"log"
 	"path/filepath"
 
+	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/builtins"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/generators"
 	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/internal/target"
+	"sigs.k8s.io/kustomize/api/internal/validate"
 	"sigs.k8s.io/kustomize/api/konfig"
 	fLdr "sigs.k8s.io/kustomize/api/loader"
 	"sigs.k8s.io/kustomize/api/provenance"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/yaml"
 )
 
 // Kustomizer performs kustomizations.
@@ -73,6 +79,10 @@ func (b *Kustomizer) Run(
 	if err != nil {
 		return nil, err
 	}
+	err = kt.EditComponents(b.options.Components, b.options.WithoutComponents)
+	if err != nil {
+		return nil, err
+	}
 	var bytes []byte
 	if openApiPath, exists := kt.Kustomization().OpenAPI["path"]; exists {
 		bytes, err = ldr.Load(filepath.Join(ldr.Root(), openApiPath))
@@ -89,7 +99,26 @@ func (b *Kustomizer) Run(
 	if err != nil {
 		return nil, err
 	}
-	if b.options.DoLegacyResourceSort {
+	err = filterResources(m, b.options.Include, b.options.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	err = b.emitSecrets(fSys, m)
+	if err != nil {
+		return nil, err
+	}
+	if b.options.SchemaValidation != types.SchemaValidationNone {
+		err = b.validateSchema(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if b.options.DoCustomResourceSort {
+		err = sortResources(m, kt.Kustomization().SortOptions)
+		if err != nil {
+			return nil, err
+		}
+	} else if b.options.DoLegacyResourceSort {
 		err = builtins.NewLegacyOrderTransformerPlugin().Transform(m)
 		if err != nil {
 			return nil, err
@@ -110,6 +139,135 @@ func (b *Kustomizer) Run(
 			return nil, err
 		}
 	}
-	m.RemoveBuildAnnotations()
+	if !b.options.KeepBuildAnnotations {
+		m.RemoveBuildAnnotations()
+	}
 	return m, nil
 }
+
+// filterResources removes the resources matching no include selector,
+// if there are any, and those matching an exclude selector.
+func filterResources(m resmap.ResMap, include, exclude []types.Selector) error {
+	if len(include) > 0 {
+		included := make(map[*resource.Resource]bool)
+		for _, s := range include {
+			resources, err := m.Select(s)
+			if err != nil {
+				return errors.Wrapf(err, "invalid include selector %s", s.String())
+			}
+			for _, r := range resources {
+				included[r] = true
+			}
+		}
+		for _, r := range m.Resources() {
+			if !included[r] {
+				if err := m.Remove(r.CurId()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if len(exclude) == 0 {
+		return nil
+	}
+	t := builtins.RemovalTransformerPlugin{Targets: exclude}
+	return t.Transform(m)
+}
+
+// emitSecrets rewrites the generated Secrets per the build's
+// secret output, if any, or else that of their generators.
+func (b *Kustomizer) emitSecrets(fSys filesys.FileSystem, m resmap.ResMap) error {
+	var build *generators.SecretOutputConfig
+	if b.options.SecretOutput != nil {
+		var err error
+		build, err = generators.MakeSecretOutputConfig(
+			b.options.SecretOutput, fSys.ReadFile)
+		if err != nil {
+			return errors.Wrap(err, "secret output")
+		}
+	}
+	for _, r := range m.Resources() {
+		if r.GetKind() != "Secret" || !r.IsGenerated() {
+			continue
+		}
+		c := build
+		if c == nil {
+			var err error
+			c, err = generators.SecretOutputConfigFromAnnotations(r.GetAnnotations())
+			if err != nil {
+				return errors.Wrapf(err, "secret %s", r.CurId())
+			}
+			if c == nil {
+				continue
+			}
+		}
+		emitted := r.DeepCopy()
+		sealed := c.Format == types.SecretOutputSealed
+		if sealed {
+			// Keep the build annotations out of the template
+			// of the SealedSecret, which the cluster keeps.
+			emitted.RemoveBuildAnnotations()
+		}
+		if err := c.Emit(&emitted.RNode); err != nil {
+			return errors.Wrapf(err, "emitting secret %s", r.CurId())
+		}
+		if sealed && b.options.KeepBuildAnnotations {
+			annotations := emitted.GetAnnotations()
+			for k, v := range buildAnnotations(r) {
+				annotations[k] = v
+			}
+			if err := emitted.SetAnnotations(annotations); err != nil {
+				return err
+			}
+		}
+		r.ResetRNode(emitted)
+	}
+	return nil
+}
+
+// buildAnnotations returns the annotations
+// of the resource which only direct the build.
+func buildAnnotations(r *resource.Resource) map[string]string {
+	stripped := r.DeepCopy()
+	stripped.RemoveBuildAnnotations()
+	kept := stripped.GetAnnotations()
+	result := make(map[string]string)
+	for k, v := range r.GetAnnotations() {
+		if _, ok := kept[k]; !ok {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// sortResources sorts the resources per the sort options.
+func sortResources(m resmap.ResMap, opts *types.SortOptions) error {
+	if opts == nil {
+		return fmt.Errorf(
+			"a custom resource order requires sortOptions in the kustomization")
+	}
+	c, err := yaml.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	t := builtins.NewSortTransformerPlugin()
+	if err = t.Config(nil, c); err != nil {
+		return errors.Wrap(err, "invalid sortOptions")
+	}
+	return t.Transform(m)
+}
+
+// validateSchema checks the resources against the OpenAPI schema,
+// failing or logging a warning per the SchemaValidation option.
+func (b *Kustomizer) validateSchema(m resmap.ResMap) error {
+	v, err := validate.NewSchemaValidator(m)
+	if err != nil {
+		return err
+	}
+	err = v.Validate(m)
+	if err == nil || b.options.SchemaValidation == types.SchemaValidationStrict {
+		return err
+	}
+	log.Printf("warning: %v\n", err)
+	return nil
+}
@@ -800,3 +800,117 @@ metadata:
   namespace: iter8-monitoring
 `)
 }
+
+func TestNamespaceOptionsCreate(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namespace: team-a
+namePrefix: p-
+commonLabels:
+  team: a
+namespaceOptions:
+  create: true
+  labels:
+    istio-injection: enabled
+  annotations:
+    owner: team-a@example.com
+resources:
+- service.yaml
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  annotations:
+    owner: team-a@example.com
+  labels:
+    istio-injection: enabled
+    team: a
+  name: team-a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: a
+  name: p-web
+  namespace: team-a
+spec:
+  ports:
+  - port: 80
+  selector:
+    team: a
+`)
+}
+
+func TestNamespaceOptionsCreateExisting(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namespace: team-a
+namespaceOptions:
+  create: true
+  labels:
+    istio-injection: enabled
+resources:
+- service.yaml
+- namespace.yaml
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteF("namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team-a
+  labels:
+    tier: backend
+`)
+	opts := th.MakeDefaultOptions()
+	opts.DoLegacyResourceSort = true
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    istio-injection: enabled
+    tier: backend
+  name: team-a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: team-a
+`)
+}
+
+func TestNamespaceOptionsCreateWithoutNamespace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+namespaceOptions:
+  create: true
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"namespaceOptions.create requires a namespace") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -18,6 +18,11 @@ type Options struct {
 	// order as specified by the kustomization file(s).
 	DoLegacyResourceSort bool
 
+	// When true, sort the resources before emitting them per the
+	// sortOptions of the kustomization, which it must have,
+	// instead of the legacy order.
+	DoCustomResourceSort bool
+
 	// When true, a label
 	//     app.kubernetes.io/managed-by: kustomize-<version>
 	// is added to all the resources in the build out.
@@ -32,16 +37,53 @@ type Options struct {
 
 	// Options related to kustomize plugins.
 	PluginConfig *types.PluginConfig
+
+	// Whether to check the resources against the OpenAPI schema
+	// after they've been customized, and whether violations fail
+	// the build or are only logged.
+	SchemaValidation types.SchemaValidation
+
+	// When true, the annotations recording the previous ids of the
+	// resources, e.g. before a name prefix was added, are kept in the
+	// output, so that tools can find resource.OrgId.
+	KeepBuildAnnotations bool
+
+	// Paths of components to add to the components of the
+	// kustomization being built, as if listed in its file.
+	Components []string
+
+	// Paths of components to drop from the components of the
+	// kustomization being built.  It's an error to drop a
+	// component the kustomization doesn't list.
+	WithoutComponents []string
+
+	// If not empty, only the resources matching any of these
+	// selectors are put out.  As in any types.Selector, the group,
+	// version, kind, name and namespace are regular expressions
+	// matching the whole field, so kind "Role" doesn't match
+	// RoleBinding, while "Role.*" does.
+	Include []types.Selector
+
+	// The resources matching any of these selectors aren't put out,
+	// even if they match an Include selector.
+	Exclude []types.Selector
+
+	// If not nil, how to emit all generated Secrets, overriding
+	// the outputs of their generators.  A certificate path is
+	// read from the file system the build reads.
+	SecretOutput *types.SecretOutput
 }
 
 // MakeDefaultOptions returns a default instance of Options.
 func MakeDefaultOptions() *Options {
 	return &Options{
 		DoLegacyResourceSort: false,
+		DoCustomResourceSort: false,
 		AddManagedbyLabel:    false,
 		LoadRestrictions:     types.LoadRestrictionsRootOnly,
 		DoPrune:              false,
 		PluginConfig:         types.DisabledPluginConfig(),
+		SchemaValidation:     types.SchemaValidationNone,
 	}
 }
 
@@ -0,0 +1,226 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeTenantBase(th kusttest_test.Harness) {
+	th.WriteF("base/configmaps.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-a
+data:
+  plan: free
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-b
+data:
+  plan: free
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-c
+data:
+  plan: free
+`)
+	th.WriteK("base", `
+resources:
+- configmaps.yaml
+`)
+}
+
+func TestPatchesFromGlob(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeTenantBase(th)
+	th.WriteF("overlay/patches/tenant-a.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-a
+data:
+  plan: gold
+`)
+	th.WriteF("overlay/patches/tenant-b.yaml", `
+- op: replace
+  path: /data/plan
+  value: silver
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+patches:
+- path: patches/tenant-a.yaml
+- path: patches/tenant-b*
+  target:
+    name: tenant-b
+`)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  plan: gold
+kind: ConfigMap
+metadata:
+  name: tenant-a
+---
+apiVersion: v1
+data:
+  plan: silver
+kind: ConfigMap
+metadata:
+  name: tenant-b
+---
+apiVersion: v1
+data:
+  plan: free
+kind: ConfigMap
+metadata:
+  name: tenant-c
+`)
+}
+
+func TestPatchesFromDirectoryOfSmPatches(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeTenantBase(th)
+	th.WriteF("overlay/patches/tenant-c.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-c
+data:
+  plan: bronze
+`)
+	th.WriteF("overlay/patches/tenant-a.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-a
+data:
+  plan: gold
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+patches:
+- path: patches
+`)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  plan: gold
+kind: ConfigMap
+metadata:
+  name: tenant-a
+---
+apiVersion: v1
+data:
+  plan: free
+kind: ConfigMap
+metadata:
+  name: tenant-b
+---
+apiVersion: v1
+data:
+  plan: bronze
+kind: ConfigMap
+metadata:
+  name: tenant-c
+`)
+}
+
+func TestPatchesFromMultiDocumentFile(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeTenantBase(th)
+	th.WriteF("overlay/patches.yaml", `# Copyright header
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-a
+data:
+  plan: gold
+--- # tenant-b moves up
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-b
+data:
+  plan: silver
+---
+# nothing for tenant-c
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+patches:
+- path: patches.yaml
+`)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  plan: gold
+kind: ConfigMap
+metadata:
+  name: tenant-a
+---
+apiVersion: v1
+data:
+  plan: silver
+kind: ConfigMap
+metadata:
+  name: tenant-b
+---
+apiVersion: v1
+data:
+  plan: free
+kind: ConfigMap
+metadata:
+  name: tenant-c
+`)
+}
+
+func TestPatchGlobHonorsLoadRestrictions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeTenantBase(th)
+	th.WriteF("patches/tenant-a.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: tenant-a
+data:
+  plan: gold
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+patches:
+- path: ../patches/*.yaml
+`)
+	err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is not in or below")
+	}
+
+	th.WriteK("overlay", `
+resources:
+- ../base
+patches:
+- path: patches/*.json
+`)
+	err = th.RunWithErr("overlay", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no files match 'patches/*.json'")
+	}
+}
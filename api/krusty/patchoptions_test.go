@@ -0,0 +1,255 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writePatchOptionsBase(th kusttest_test.Harness) {
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteF("patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: wbe
+spec:
+  replicas: 2
+`)
+}
+
+const unpatchedDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`
+
+func TestPatchNoMatchIsNoOpByDefault(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePatchOptionsBase(th)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patches:
+- target:
+    name: wbe
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: wbe
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, unpatchedDeployment)
+}
+
+func TestPatchFailIfNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePatchOptionsBase(th)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patches:
+- target:
+    kind: Deployment
+    name: wbe
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+  options:
+    failIfNoMatch: true
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"patch matches no resources; target: ~G_~V_Deployment|~X|wbe:a=:l=")
+	}
+}
+
+func TestPatchJson6902FailIfNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePatchOptionsBase(th)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: wbe
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+  options:
+    failIfNoMatch: true
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"patch matches no resources; target: apps_v1_Deployment|~X|wbe:a=:l=")
+	}
+}
+
+func TestPatchFailIfNoMatchGlobally(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePatchOptionsBase(th)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: wbe
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+`)
+	options := th.MakeDefaultOptions()
+	options.PluginConfig.PatchConfig.FailIfNoMatch = true
+	err := th.RunWithErr(".", options)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"patch matches no resources; target: apps_v1_Deployment|~X|wbe:a=:l=")
+	}
+}
+
+func TestOptionalPatchOverridesFailIfNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePatchOptionsBase(th)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patches:
+- target:
+    name: wbe
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+  options:
+    optional: true
+- path: patch.yaml
+  options:
+    optional: true
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: wbe
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+  options:
+    optional: true
+`)
+	options := th.MakeDefaultOptions()
+	options.PluginConfig.PatchConfig.FailIfNoMatch = true
+	m := th.Run(".", options)
+	th.AssertActualEqualsExpected(m, unpatchedDeployment)
+}
+
+func TestStrategicMergePatchWithoutMatchFails(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePatchOptionsBase(th)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"failed to find unique target for patch apps_v1_Deployment|wbe")
+	}
+}
+
+func TestStrategicMergePatchOptions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePatchOptionsBase(th)
+	th.WriteF("replicas.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- path: patch.yaml
+  options:
+    optional: true
+- replicas.yaml
+`)
+	options := th.MakeDefaultOptions()
+	options.PluginConfig.PatchConfig.FailIfNoMatch = true
+	m := th.Run(".", options)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- path: patch.yaml
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"failed to find unique target for patch apps_v1_Deployment|wbe")
+	}
+
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- path: patch.yaml
+  target:
+    name: web
+`)
+	err = th.RunWithErr(".", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"an entry of patchesStrategicMerge may only hold a path and options, not target")
+	}
+}
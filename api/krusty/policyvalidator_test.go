@@ -0,0 +1,92 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestPolicyValidator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+`)
+	th.WriteF("policy.yaml", `
+apiVersion: builtin
+kind: PolicyValidator
+metadata:
+  name: policy
+rules:
+- name: team
+  requiredLabels:
+  - team
+- name: registry
+  target:
+    kind: Deployment
+  allowedImageRegistries:
+  - gcr.io/my-project
+`)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+validators:
+- policy.yaml
+`)
+	err := th.RunWithErr(".", th.MakeDefaultOptions())
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), `2 policy violation(s):
+  rule team: apps_v1_Deployment|~X|nginx: missing required label "team"
+  rule registry: apps_v1_Deployment|~X|nginx: container "nginx" image "nginx" is not from an allowed registry [gcr.io/my-project]`)
+
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+commonLabels:
+  team: a
+images:
+- name: nginx
+  newName: gcr.io/my-project/nginx
+validators:
+- policy.yaml
+`)
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: a
+  name: nginx
+spec:
+  selector:
+    matchLabels:
+      team: a
+  template:
+    metadata:
+      labels:
+        team: a
+    spec:
+      containers:
+      - image: gcr.io/my-project/nginx
+        name: nginx
+`)
+}
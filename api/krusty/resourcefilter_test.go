@@ -0,0 +1,154 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+func writeFilterResources(th kusttest_test.Harness) {
+	th.WriteK("base", `
+namePrefix: p-
+resources:
+- resources.yaml
+`)
+	th.WriteF("base/resources.yaml", `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: reader
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: reader
+roleRef:
+  kind: Role
+  name: reader
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`)
+}
+
+func TestResourceFilter(t *testing.T) {
+	kind := func(k string) types.Selector {
+		return types.Selector{ResId: resid.ResId{Gvk: resid.Gvk{Kind: k}}}
+	}
+	testCases := map[string]struct {
+		include        []types.Selector
+		exclude        []types.Selector
+		expectedOutput string
+	}{
+		"include": {
+			include: []types.Selector{kind("Role.*")},
+			expectedOutput: `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: p-reader
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: p-reader
+roleRef:
+  kind: Role
+  name: p-reader
+`,
+		},
+		"anchored kind": {
+			include: []types.Selector{kind("Role")},
+			expectedOutput: `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: p-reader
+`,
+		},
+		"exclude": {
+			exclude: []types.Selector{kind("Secret"), kind("Role.*")},
+			expectedOutput: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-settings
+`,
+		},
+		"include and exclude": {
+			include: []types.Selector{kind("Role.*"), kind("Secret")},
+			exclude: []types.Selector{kind("Secret"), kind("RoleBinding")},
+			expectedOutput: `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: p-reader
+`,
+		},
+		"by original name": {
+			include: []types.Selector{{ResId: resid.ResId{Name: "settings"}}},
+			expectedOutput: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-settings
+`,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeFilterResources(th)
+			opts := th.MakeDefaultOptions()
+			opts.Include = tc.include
+			opts.Exclude = tc.exclude
+			m := th.Run("base", opts)
+			th.AssertActualEqualsExpected(m, tc.expectedOutput)
+		})
+	}
+}
+
+func TestRemovalTransformerInKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFilterResources(th)
+	th.WriteK("overlay", `
+resources:
+- ../base
+transformers:
+- removal.yaml
+`)
+	th.WriteF("overlay/removal.yaml", `
+apiVersion: builtin
+kind: RemovalTransformer
+metadata:
+  name: drop-secrets
+targets:
+- kind: Secret
+- name: p-reader
+  kind: RoleBinding
+`)
+	m := th.Run("overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: p-reader
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-settings
+`)
+}
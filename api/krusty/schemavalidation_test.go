@@ -0,0 +1,130 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeInvalidDeployment(th kusttest_test.Harness) {
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: three
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+        imagePullPolcy: Always
+`)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: my-
+resources:
+- deployment.yaml
+`)
+}
+
+func TestSchemaValidationStrict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInvalidDeployment(th)
+	options := th.MakeDefaultOptions()
+	options.SchemaValidation = types.SchemaValidationStrict
+	err := th.RunWithErr(".", options)
+	assert.Equal(t, `2 schema violation(s):
+  apps_v1_Deployment|~X|my-nginx: spec.replicas: expected integer, got string
+  apps_v1_Deployment|~X|my-nginx: spec.template.spec.containers[name=nginx].imagePullPolcy: unknown field`,
+		err.Error())
+}
+
+func TestSchemaValidationWarn(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInvalidDeployment(th)
+	options := th.MakeDefaultOptions()
+	options.SchemaValidation = types.SchemaValidationWarn
+	m := th.Run(".", options)
+	assert.Equal(t, 1, m.Size())
+}
+
+func TestSchemaValidationNone(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInvalidDeployment(th)
+	m := th.Run(".", th.MakeDefaultOptions())
+	assert.Equal(t, 1, m.Size())
+}
+
+func TestSchemaValidationCrd(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("crd.yaml", `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+    plural: crontabs
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - image
+            properties:
+              cronSpec:
+                type: string
+              image:
+                type: string
+              replicas:
+                type: integer
+`)
+	th.WriteF("crontab.yaml", `
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: my-cron
+spec:
+  cronSpec: "* * * * */5"
+  replicas: "2"
+  imag: my-cron-image
+`)
+	th.WriteK(".", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- crd.yaml
+- crontab.yaml
+`)
+	options := th.MakeDefaultOptions()
+	options.SchemaValidation = types.SchemaValidationStrict
+	err := th.RunWithErr(".", options)
+	assert.Equal(t, `3 schema violation(s):
+  stable.example.com_v1_CronTab|~X|my-cron: spec.replicas: expected integer, got string
+  stable.example.com_v1_CronTab|~X|my-cron: spec.imag: unknown field
+  stable.example.com_v1_CronTab|~X|my-cron: spec.image: missing required field`,
+		err.Error())
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func writeSecretOutputBase(th kusttest_test.Harness) {
+	th.WriteK("base", `
+secretGenerator:
+- name: db
+  literals:
+  - password=secret
+  output:
+    format: stringData
+resources:
+- deployment.yaml
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        envFrom:
+        - secretRef:
+            name: db
+`)
+}
+
+func TestSecretOutputStringData(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSecretOutputBase(th)
+	m := th.Run("base", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - secretRef:
+            name: db-m4d885dchh
+        name: app
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-m4d885dchh
+stringData:
+  password: secret
+type: Opaque
+`)
+}
+
+func TestSecretOutputSealedByBuild(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th := kusttest_test.MakeHarness(t)
+	writeSecretOutputBase(th)
+	th.WriteF("cert.pem", string(pem.EncodeToMemory(
+		&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})))
+	th.WriteK("overlay", `
+namespace: prod
+namePrefix: prod-
+resources:
+- ../base
+`)
+	opts := th.MakeDefaultOptions()
+	opts.SecretOutput = &types.SecretOutput{
+		Format:      types.SecretOutputSealed,
+		Certificate: "cert.pem",
+	}
+	m := th.Run("overlay", opts)
+	sealed := m.Resources()[1]
+	password, err := sealed.Pipe(yaml.Lookup("spec", "encryptedData", "password"))
+	if err != nil || password == nil {
+		t.Fatalf("no sealed password: %v", err)
+	}
+	// The password is bound to the final name and namespace.
+	ciphertext, err := base64.StdEncoding.DecodeString(password.YNode().Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key,
+		ciphertext[2:2+n], []byte("prod/prod-db-m4d885dchh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := aead.Open(
+		nil, make([]byte, aead.NonceSize()), ciphertext[2+n:], nil)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatalf("unexpected password %q: %v", plaintext, err)
+	}
+	password.YNode().Value = "..."
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-app
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - secretRef:
+            name: prod-db-m4d885dchh
+        name: app
+---
+apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: prod-db-m4d885dchh
+  namespace: prod
+spec:
+  encryptedData:
+    password: '...'
+  template:
+    metadata:
+      name: prod-db-m4d885dchh
+      namespace: prod
+    type: Opaque
+`)
+
+	opts.SecretOutput.Certificate = "missing.pem"
+	err = th.RunWithErr("overlay", opts)
+	if !strings.Contains(err.Error(), "secret output") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSecretOutputKeepsBuildAnnotations(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSecretOutputBase(th)
+	th.WriteK("overlay", `
+namePrefix: prod-
+namespace: prod
+resources:
+- ../base
+`)
+	opts := th.MakeDefaultOptions()
+	opts.KeepBuildAnnotations = true
+	m := th.Run("overlay", opts)
+	secret := m.Resources()[1]
+	expected := map[string]string{
+		"config.kubernetes.io/prefixes":           "prod-",
+		"config.kubernetes.io/previousKinds":      "Secret,Secret,Secret",
+		"config.kubernetes.io/previousNames":      "db,db,prod-db",
+		"config.kubernetes.io/previousNamespaces": "default,prod,prod",
+		types.SecretOutputAnnotation:              `{"format":"stringData"}`,
+	}
+	assert.Equal(t, expected, secret.GetAnnotations())
+
+	// A SealedSecret keeps them too, but not its template.
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th.WriteF("cert.pem", string(pem.EncodeToMemory(
+		&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})))
+	opts.SecretOutput = &types.SecretOutput{
+		Format:      types.SecretOutputSealed,
+		Certificate: "cert.pem",
+	}
+	m = th.Run("overlay", opts)
+	sealed := m.Resources()[1]
+	assert.Equal(t, "SealedSecret", sealed.GetKind())
+	for k, v := range expected {
+		if k != types.SecretOutputAnnotation {
+			assert.Equal(t, v, sealed.GetAnnotations()[k])
+		}
+	}
+	template, err := sealed.Pipe(yaml.Lookup("spec", "template"))
+	if !assert.NoError(t, err) || !assert.NotNil(t, template) {
+		t.FailNow()
+	}
+	assert.Empty(t, template.GetAnnotations())
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeSortResources(th kusttest_test.Harness) {
+	th.WriteF("resources.yaml", `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: hook
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apps
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+`)
+}
+
+func TestCustomResourceSort(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortResources(th)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+sortOptions:
+  first:
+  - kind: CustomResourceDefinition
+  - kind: Namespace
+  - group: example.com
+  last:
+  - group: admissionregistration.k8s.io
+`)
+	opts := th.MakeDefaultOptions()
+	opts.DoCustomResourceSort = true
+	m := th.Run(".", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apps
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: hook
+`)
+
+	// without the option, the sortOptions don't matter
+	m = th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: hook
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apps
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+`)
+}
+
+func TestCustomResourceSortErrors(t *testing.T) {
+	testCases := map[string]struct {
+		kustomization string
+		expectedError string
+	}{
+		"no sortOptions": {
+			kustomization: `
+resources:
+- resources.yaml
+`,
+			expectedError: "a custom resource order requires sortOptions in the kustomization",
+		},
+		"invalid sortOptions": {
+			kustomization: `
+resources:
+- resources.yaml
+sortOptions:
+  tieBreakers:
+  - age
+`,
+			expectedError: "invalid sortOptions: unknown tie-breaker 'age'",
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeSortResources(th)
+			th.WriteK(".", tc.kustomization)
+			opts := th.MakeDefaultOptions()
+			opts.DoCustomResourceSort = true
+			err := th.RunWithErr(".", opts)
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+var _ ifc.DirectoryKvLoader = &loader{}
+
+// DirectoryItems implements ifc.DirectoryKvLoader.
+func (kvl *loader) DirectoryItems(
+	sources []types.DirectorySource) ([]types.KeyToPath, error) {
+	var items []types.KeyToPath
+	paths := make(map[string]string)
+	for _, s := range sources {
+		more, err := kvl.directoryItems(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range more {
+			if dir, ok := paths[item.Path]; ok {
+				return nil, fmt.Errorf(
+					"directory sources %s and %s both have a file %s",
+					dir, s.Path, item.Path)
+			}
+			paths[item.Path] = s.Path
+		}
+		items = append(items, more...)
+	}
+	return items, nil
+}
+
+func (kvl *loader) keyValuesFromDirectorySources(
+	sources []types.DirectorySource) ([]types.Pair, error) {
+	var kvs []types.Pair
+	for _, s := range sources {
+		items, err := kvl.directoryItems(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			content, err := kvl.ldr.Load(path.Join(s.Path, item.Path))
+			if err != nil {
+				return nil, err
+			}
+			kvs = append(kvs, types.Pair{Key: item.Key, Value: string(content)})
+		}
+	}
+	return kvs, nil
+}
+
+// directoryItems keys the selected files of the tree.
+func (kvl *loader) directoryItems(
+	s types.DirectorySource) ([]types.KeyToPath, error) {
+	if s.Path == "" {
+		return nil, fmt.Errorf("directory source is missing a path")
+	}
+	sep := s.KeySeparator
+	if sep == "" {
+		sep = types.DefaultDirectoryKeySeparator
+	}
+	tl, ok := kvl.ldr.(ifc.TreeLoader)
+	if !ok {
+		return nil, fmt.Errorf(
+			"directory source %s needs a loader listing directories, not %T",
+			s.Path, kvl.ldr)
+	}
+	files, err := tl.Tree(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	var items []types.KeyToPath
+	for _, f := range files {
+		selected, err := isSelectedFile(f, s.Include, s.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("directory source %s: %v", s.Path, err)
+		}
+		if selected {
+			items = append(items, types.KeyToPath{
+				Key: strings.ReplaceAll(f, "/", sep), Path: f})
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("directory source %s has no files", s.Path)
+	}
+	return items, nil
+}
+
+func isSelectedFile(f string, include, exclude []string) (bool, error) {
+	if len(include) > 0 {
+		included, err := matchesAny(f, include)
+		if err != nil || !included {
+			return false, err
+		}
+	}
+	excluded, err := matchesAny(f, exclude)
+	return !excluded, err
+}
+
+// matchesAny tells whether a pattern matches the path or, if
+// it has no slash, the base name.
+func matchesAny(f string, patterns []string) (bool, error) {
+	for _, p := range patterns {
+		name := f
+		if !strings.Contains(p, "/") {
+			name = path.Base(f)
+		}
+		matched, err := path.Match(p, name)
+		if err != nil {
+			return false, fmt.Errorf("bad pattern '%s': %v", p, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
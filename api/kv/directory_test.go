@@ -0,0 +1,133 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestDirectorySources(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("nginx/nginx.conf", []byte("main"))
+	fSys.WriteFile("nginx/conf.d/default.conf", []byte("default"))
+	fSys.WriteFile("nginx/conf.d/default.conf.bak", []byte("backup"))
+	fSys.WriteFile("nginx/snippets/ssl/params.conf", []byte("ssl"))
+	fSys.WriteFile("nginx/mime.types", []byte("mime"))
+	fSys.WriteFile("other/nginx.conf", []byte("other"))
+	kvl := makeKvLoader(fSys)
+
+	testCases := map[string]struct {
+		sources []types.DirectorySource
+		items   []types.KeyToPath
+		pairs   []types.Pair
+		err     string
+	}{
+		"whole tree": {
+			sources: []types.DirectorySource{{Path: "nginx"}},
+			items: []types.KeyToPath{
+				{Key: "conf.d_default.conf", Path: "conf.d/default.conf"},
+				{Key: "conf.d_default.conf.bak", Path: "conf.d/default.conf.bak"},
+				{Key: "mime.types", Path: "mime.types"},
+				{Key: "nginx.conf", Path: "nginx.conf"},
+				{Key: "snippets_ssl_params.conf", Path: "snippets/ssl/params.conf"},
+			},
+			pairs: []types.Pair{
+				{Key: "conf.d_default.conf", Value: "default"},
+				{Key: "conf.d_default.conf.bak", Value: "backup"},
+				{Key: "mime.types", Value: "mime"},
+				{Key: "nginx.conf", Value: "main"},
+				{Key: "snippets_ssl_params.conf", Value: "ssl"},
+			},
+		},
+		"include, exclude and separator": {
+			sources: []types.DirectorySource{{
+				Path:         "nginx",
+				KeySeparator: "--",
+				Include:      []string{"*.conf", "mime.*"},
+				Exclude:      []string{"snippets/*/*"},
+			}},
+			items: []types.KeyToPath{
+				{Key: "conf.d--default.conf", Path: "conf.d/default.conf"},
+				{Key: "mime.types", Path: "mime.types"},
+				{Key: "nginx.conf", Path: "nginx.conf"},
+			},
+			pairs: []types.Pair{
+				{Key: "conf.d--default.conf", Value: "default"},
+				{Key: "mime.types", Value: "mime"},
+				{Key: "nginx.conf", Value: "main"},
+			},
+		},
+		"no path": {
+			sources: []types.DirectorySource{{}},
+			err:     "directory source is missing a path",
+		},
+		"not a directory": {
+			sources: []types.DirectorySource{{Path: "nginx/nginx.conf"}},
+			err:     "'nginx/nginx.conf' must be a directory",
+		},
+		"no files": {
+			sources: []types.DirectorySource{{
+				Path: "nginx", Include: []string{"*.json"}}},
+			err: "directory source nginx has no files",
+		},
+		"bad pattern": {
+			sources: []types.DirectorySource{{
+				Path: "nginx", Exclude: []string{"["}}},
+			err: "directory source nginx: bad pattern '[': syntax error in pattern",
+		},
+		"same file in two directories": {
+			sources: []types.DirectorySource{
+				{Path: "nginx", Include: []string{"nginx.conf"}},
+				{Path: "other"},
+			},
+			err: "directory sources nginx and other both have a file nginx.conf",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			items, err := kvl.DirectoryItems(tc.sources)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(items, tc.items) {
+				t.Fatalf("expected %v, got %v", tc.items, items)
+			}
+			pairs, err := kvl.Load(types.KvPairSources{DirectorySources: tc.sources})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(pairs, tc.pairs) {
+				t.Fatalf("expected %v, got %v", tc.pairs, pairs)
+			}
+		})
+	}
+}
+
+// plainLoader has only the methods of ifc.Loader.
+type plainLoader struct {
+	ifc.Loader
+}
+
+func TestDirectorySourcesWithoutTrees(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("nginx/nginx.conf", []byte("main"))
+	kvl := makeKvLoader(fSys)
+	kvl.ldr = plainLoader{kvl.ldr}
+	_, err := kvl.DirectoryItems([]types.DirectorySource{{Path: "nginx"}})
+	expected := "directory source nginx needs a loader listing directories, not kv.plainLoader"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func (kvl *loader) keyValuesFromDotenvFiles(
+	paths []string, expand bool) ([]types.Pair, error) {
+	var kvs []types.Pair
+	// Values may refer to keys of earlier files.
+	vars := make(map[string]string)
+	for _, p := range paths {
+		content, err := kvl.ldr.Load(p)
+		if err != nil {
+			return nil, err
+		}
+		more, err := kvl.keyValuesFromDotenv(content, vars, expand)
+		if err != nil {
+			return nil, errors.Wrap(err, p)
+		}
+		kvs = append(kvs, more...)
+	}
+	return kvs, nil
+}
+
+// keyValuesFromDotenv parses dotenv content, e.g.
+//
+//	# a comment
+//	export NAME=value       # a comment
+//	SINGLE='kept as is: \n ${NAME}'
+//	DOUBLE="escaped: \n \" \\ \$"
+//	MULTI="first line
+//	second line"
+//	FROM_ENVIRONMENT
+//
+// Like a plain env file, a key without a value takes its
+// value from the environment.  If expand, variables in
+// unquoted and double quoted values are replaced with the
+// values of the keys before them, held by vars, which is
+// updated with the new pairs.
+func (kvl *loader) keyValuesFromDotenv(
+	content []byte, vars map[string]string, expand bool) ([]types.Pair, error) {
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("invalid utf8 bytes")
+	}
+	content = bytes.TrimPrefix(content, utf8bom)
+	p := &dotenvParser{
+		src:    []rune(strings.ReplaceAll(string(content), "\r\n", "\n")),
+		line:   1,
+		vars:   vars,
+		expand: expand,
+	}
+	var kvs []types.Pair
+	for {
+		p.skipBlankLinesAndComments()
+		if p.done() {
+			return kvs, nil
+		}
+		line := p.line
+		kv, err := p.parsePair()
+		if err == nil {
+			err = kvl.validator.IsEnvVarName(kv.Key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		vars[kv.Key] = kv.Value
+		kvs = append(kvs, kv)
+	}
+}
+
+// dotenvParser reads pairs from dotenv content.
+type dotenvParser struct {
+	src    []rune
+	pos    int
+	line   int
+	vars   map[string]string
+	expand bool
+}
+
+func (p *dotenvParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) next() rune {
+	r := p.peek()
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for r := p.peek(); r == ' ' || r == '\t'; r = p.peek() {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipToEndOfLine() {
+	for !p.done() && p.peek() != '\n' {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipBlankLinesAndComments() {
+	for !p.done() {
+		switch p.peek() {
+		case ' ', '\t', '\n':
+			p.next()
+		case '#':
+			p.skipToEndOfLine()
+		default:
+			return
+		}
+	}
+}
+
+// readKey reads up to a space, '=' or the end of the line.
+func (p *dotenvParser) readKey() string {
+	start := p.pos
+	for r := p.peek(); !p.done() &&
+		r != '=' && r != ' ' && r != '\t' && r != '\n'; r = p.peek() {
+		p.next()
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *dotenvParser) parsePair() (types.Pair, error) {
+	key := p.readKey()
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.readKey()
+	}
+	p.skipSpaces()
+	if p.done() || p.peek() == '\n' || p.peek() == '#' {
+		// No value is a signal to obtain it from the environment.
+		p.skipToEndOfLine()
+		return types.Pair{Key: key, Value: os.Getenv(key)}, nil
+	}
+	if p.next() != '=' {
+		return types.Pair{}, fmt.Errorf("expected '=' after key %q", key)
+	}
+	p.skipSpaces()
+	var value string
+	var err error
+	switch p.peek() {
+	case '\'':
+		value, err = p.readSingleQuoted()
+	case '"':
+		value, err = p.readDoubleQuoted()
+	default:
+		value, err = p.readUnquoted()
+	}
+	if err != nil {
+		return types.Pair{}, err
+	}
+	return types.Pair{Key: key, Value: value}, nil
+}
+
+// readSingleQuoted reads a value kept as is.
+func (p *dotenvParser) readSingleQuoted() (string, error) {
+	p.next()
+	var b strings.Builder
+	for {
+		if p.done() {
+			return "", fmt.Errorf("unterminated single quoted value")
+		}
+		r := p.next()
+		if r == '\'' {
+			return b.String(), p.endQuotedValue()
+		}
+		b.WriteRune(r)
+	}
+}
+
+// readDoubleQuoted reads a value with escapes and, if
+// expanding, variables.
+func (p *dotenvParser) readDoubleQuoted() (string, error) {
+	p.next()
+	var b strings.Builder
+	for {
+		if p.done() {
+			return "", fmt.Errorf("unterminated double quoted value")
+		}
+		r := p.next()
+		switch r {
+		case '"':
+			return b.String(), p.endQuotedValue()
+		case '\\':
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteRune('\n')
+			case 'r':
+				b.WriteRune('\r')
+			case 't':
+				b.WriteRune('\t')
+			case '"', '\\', '$':
+				b.WriteRune(e)
+			default:
+				b.WriteRune('\\')
+				b.WriteRune(e)
+			}
+		case '$':
+			if err := p.writeVariable(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// endQuotedValue allows only a comment after a quoted value.
+func (p *dotenvParser) endQuotedValue() error {
+	p.skipSpaces()
+	switch p.peek() {
+	case 0, '\n':
+		return nil
+	case '#':
+		p.skipToEndOfLine()
+		return nil
+	}
+	return fmt.Errorf("unexpected %q after quoted value", p.peek())
+}
+
+// readUnquoted reads a value up to the end of the line or
+// a comment, which starts with a '#' following a space.
+func (p *dotenvParser) readUnquoted() (string, error) {
+	var b strings.Builder
+	for !p.done() && p.peek() != '\n' {
+		if p.peek() == '#' && p.pos > 0 &&
+			(p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			p.skipToEndOfLine()
+			break
+		}
+		r := p.next()
+		if r == '$' {
+			if err := p.writeVariable(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimRight(b.String(), " \t"), nil
+}
+
+func isVariableNameRune(r rune, first bool) bool {
+	return r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') ||
+		(!first && r >= '0' && r <= '9')
+}
+
+// writeVariable writes the value of the variable following
+// a '$', or the '$' itself if not expanding or no variable
+// follows.
+func (p *dotenvParser) writeVariable(b *strings.Builder) error {
+	if !p.expand {
+		b.WriteRune('$')
+		return nil
+	}
+	braced := p.peek() == '{'
+	if braced {
+		p.next()
+	} else if !isVariableNameRune(p.peek(), true) {
+		b.WriteRune('$')
+		return nil
+	}
+	start := p.pos
+	for first := true; isVariableNameRune(p.peek(), first); first = false {
+		p.next()
+	}
+	name := string(p.src[start:p.pos])
+	value, defined := p.vars[name]
+	if !braced {
+		if !defined {
+			return fmt.Errorf("undefined variable %s", name)
+		}
+		b.WriteString(value)
+		return nil
+	}
+	if name == "" {
+		return fmt.Errorf("invalid variable reference")
+	}
+	useDefault := !defined
+	switch {
+	case p.peek() == ':' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '-':
+		p.next()
+		p.next()
+		useDefault = !defined || value == ""
+	case p.peek() == '-':
+		p.next()
+	case p.peek() == '}':
+		p.next()
+		if !defined {
+			return fmt.Errorf("undefined variable %s", name)
+		}
+		b.WriteString(value)
+		return nil
+	default:
+		return fmt.Errorf("invalid reference to variable %s", name)
+	}
+	var fallback strings.Builder
+	for {
+		if p.done() || p.peek() == '\n' {
+			return fmt.Errorf("unterminated reference to variable %s", name)
+		}
+		r := p.next()
+		if r == '}' {
+			break
+		}
+		fallback.WriteRune(r)
+	}
+	if useDefault {
+		value = fallback.String()
+	}
+	b.WriteString(value)
+	return nil
+}
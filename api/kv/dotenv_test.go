@@ -0,0 +1,246 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestKeyValuesFromDotenv(t *testing.T) {
+	testCases := map[string]struct {
+		content  string
+		expand   bool
+		expected []types.Pair
+		err      string
+	}{
+		"plain": {
+			content: "A=1\nB=two words\n",
+			expected: []types.Pair{
+				{Key: "A", Value: "1"},
+				{Key: "B", Value: "two words"},
+			},
+		},
+		"blank lines, comments and spaces": {
+			content: "\ufeff# comment\n\n  A = 1  \r\n\t# indented comment\nB=\nC= # empty\n",
+			expected: []types.Pair{
+				{Key: "A", Value: "1"},
+				{Key: "B", Value: ""},
+				{Key: "C", Value: ""},
+			},
+		},
+		"export": {
+			content: "export A=1\nexport\tB=2\nexport=3\n",
+			expected: []types.Pair{
+				{Key: "A", Value: "1"},
+				{Key: "B", Value: "2"},
+				{Key: "export", Value: "3"},
+			},
+		},
+		"inline comments": {
+			content: "A=1 # one\nB=x#y\nC='q' # quoted\nD=\"q\"\t# quoted\n",
+			expected: []types.Pair{
+				{Key: "A", Value: "1"},
+				{Key: "B", Value: "x#y"},
+				{Key: "C", Value: "q"},
+				{Key: "D", Value: "q"},
+			},
+		},
+		"single quotes": {
+			content: `A='  spaced  '
+B='no \n escapes, # no comment, no $A'
+C=''
+`,
+			expected: []types.Pair{
+				{Key: "A", Value: "  spaced  "},
+				{Key: "B", Value: `no \n escapes, # no comment, no $A`},
+				{Key: "C", Value: ""},
+			},
+		},
+		"double quotes": {
+			content: `A="  spaced  "
+B="escapes: \n \r \t \" \\ \$ \q"
+C="# no comment"
+D="it's"
+`,
+			expected: []types.Pair{
+				{Key: "A", Value: "  spaced  "},
+				{Key: "B", Value: "escapes: \n \r \t \" \\ $ \\q"},
+				{Key: "C", Value: "# no comment"},
+				{Key: "D", Value: "it's"},
+			},
+		},
+		"unquoted values keep quotes and backslashes inside": {
+			content: `A=it's "quoted" \n`,
+			expected: []types.Pair{
+				{Key: "A", Value: `it's "quoted" \n`},
+			},
+		},
+		"multi-line values": {
+			content: "A=\"first\nsecond\"\nB='one\r\ntwo'\nC=3\n",
+			expected: []types.Pair{
+				{Key: "A", Value: "first\nsecond"},
+				{Key: "B", Value: "one\ntwo"},
+				{Key: "C", Value: "3"},
+			},
+		},
+		"no expansion": {
+			content: "A=1\nB=$A ${A}\nC=\"${A}\"\n",
+			expected: []types.Pair{
+				{Key: "A", Value: "1"},
+				{Key: "B", Value: "$A ${A}"},
+				{Key: "C", Value: "${A}"},
+			},
+		},
+		"expansion": {
+			content: `HOST=db
+PORT=5432
+EMPTY=
+URL=postgres://${HOST}:$PORT/app
+QUOTED="${HOST}_$PORT \${HOST}"
+SINGLE='${HOST}'
+DEFAULTS=${MISSING:-a} ${MISSING-b} ${EMPTY:-c} ${EMPTY-d} ${HOST:-e}
+DOLLARS=$ $5 cost$
+`,
+			expand: true,
+			expected: []types.Pair{
+				{Key: "HOST", Value: "db"},
+				{Key: "PORT", Value: "5432"},
+				{Key: "EMPTY", Value: ""},
+				{Key: "URL", Value: "postgres://db:5432/app"},
+				{Key: "QUOTED", Value: "db_5432 ${HOST}"},
+				{Key: "SINGLE", Value: "${HOST}"},
+				{Key: "DEFAULTS", Value: "a b c  db"},
+				{Key: "DOLLARS", Value: "$ $5 cost$"},
+			},
+		},
+		"expansion of later key": {
+			content: "A=$B\nB=1\n",
+			expand:  true,
+			err:     "line 1: undefined variable B",
+		},
+		"undefined braced variable": {
+			content: "A=1\nB=\"${C}\"\n",
+			expand:  true,
+			err:     "line 2: undefined variable C",
+		},
+		"invalid reference": {
+			content: "A=${B:x}\n",
+			expand:  true,
+			err:     "line 1: invalid reference to variable B",
+		},
+		"unterminated reference": {
+			content: "A=${B:-x\n",
+			expand:  true,
+			err:     "line 1: unterminated reference to variable B",
+		},
+		"unterminated single quote": {
+			content: "A=1\nB='x\n",
+			err:     "line 2: unterminated single quoted value",
+		},
+		"unterminated double quote": {
+			content: "A=\"x\n",
+			err:     "line 1: unterminated double quoted value",
+		},
+		"text after quotes": {
+			content: "A='x' y\n",
+			err:     `line 1: unexpected 'y' after quoted value`,
+		},
+		"missing equals": {
+			content: "A B\n",
+			err:     `line 1: expected '=' after key "A"`,
+		},
+		"invalid utf8": {
+			content: "A=\xff\n",
+			err:     "invalid utf8 bytes",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			kvl := makeKvLoader(filesys.MakeFsInMemory())
+			pairs, err := kvl.keyValuesFromDotenv(
+				[]byte(tc.content), make(map[string]string), tc.expand)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(pairs, tc.expected) {
+				t.Fatalf("expected %q, got %q", tc.expected, pairs)
+			}
+		})
+	}
+}
+
+func TestKeyValuesFromDotenvEnvironment(t *testing.T) {
+	setEnv(t, "KUSTOMIZE_DOTENV_TEST", "from env")
+	kvl := makeKvLoader(filesys.MakeFsInMemory())
+	pairs, err := kvl.keyValuesFromDotenv(
+		[]byte("KUSTOMIZE_DOTENV_TEST\nexport KUSTOMIZE_DOTENV_UNSET # c\n"),
+		make(map[string]string), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []types.Pair{
+		{Key: "KUSTOMIZE_DOTENV_TEST", Value: "from env"},
+		{Key: "KUSTOMIZE_DOTENV_UNSET", Value: os.Getenv("KUSTOMIZE_DOTENV_UNSET")},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("expected %q, got %q", expected, pairs)
+	}
+}
+
+func TestLoadEnvFormats(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("a.env", []byte("HOST=db\n"))
+	fSys.WriteFile("b.env", []byte("URL=\"http://${HOST}\"\n"))
+	kvl := makeKvLoader(fSys)
+
+	pairs, err := kvl.Load(types.KvPairSources{
+		EnvSources:    []string{"a.env", "b.env"},
+		EnvFormat:     types.EnvFormatDotenv,
+		ExpandEnvVars: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []types.Pair{
+		{Key: "HOST", Value: "db"},
+		{Key: "URL", Value: "http://db"},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("expected %q, got %q", expected, pairs)
+	}
+
+	pairs, err = kvl.Load(types.KvPairSources{EnvSources: []string{"b.env"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = []types.Pair{{Key: "URL", Value: `"http://${HOST}"`}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("expected %q, got %q", expected, pairs)
+	}
+
+	_, err = kvl.Load(types.KvPairSources{
+		EnvSources: []string{"a.env"}, ExpandEnvVars: true})
+	if err == nil || !strings.Contains(err.Error(),
+		"expandEnvVars requires the dotenv envFormat") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = kvl.Load(types.KvPairSources{
+		EnvSources: []string{"a.env"}, EnvFormat: "ini"})
+	if err == nil || !strings.Contains(err.Error(),
+		"unknown envFormat 'ini'; must be empty or dotenv") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
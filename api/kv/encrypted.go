@@ -0,0 +1,164 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	// The environment variables SOPS reads age identities from.
+	sopsAgeKeyEnv     = "SOPS_AGE_KEY"
+	sopsAgeKeyFileEnv = "SOPS_AGE_KEY_FILE"
+
+	// ageIntro starts binary age files.
+	ageIntro = "age-encryption.org/v1\n"
+)
+
+var _ ifc.EncryptedKvLoader = &loader{}
+
+// LoadEncrypted implements ifc.EncryptedKvLoader.
+func (kvl *loader) LoadEncrypted(
+	args types.EncryptedSources) ([]types.Pair, error) {
+	if len(args.EnvSources) == 0 && len(args.FileSources) == 0 {
+		return nil, nil
+	}
+	ids, err := kvl.ageIdentities(args.AgeKeys)
+	if err != nil {
+		return nil, err
+	}
+	var all []types.Pair
+	for _, p := range args.EnvSources {
+		plaintext, err := kvl.loadDecrypted(p, ids)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encrypted env source %s", p)
+		}
+		pairs, err := kvl.keyValuesFromLines(plaintext)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encrypted env source %s", p)
+		}
+		all = append(all, pairs...)
+	}
+	for _, s := range args.FileSources {
+		k, fPath, err := parseFileSource(s)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := kvl.loadDecrypted(fPath, ids)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encrypted file source %s", s)
+		}
+		all = append(all, types.Pair{Key: k, Value: string(plaintext)})
+	}
+	return all, nil
+}
+
+// loadDecrypted loads the file at the path, and decrypts it
+// with age if it's an age file, or else as a SOPS file.
+func (kvl *loader) loadDecrypted(
+	path string, ids []age.Identity) ([]byte, error) {
+	content, err := kvl.ldr.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte(armor.Header)):
+		return decryptAge(armor.NewReader(bytes.NewReader(trimmed)), ids)
+	case bytes.HasPrefix(content, []byte(ageIntro)):
+		return decryptAge(bytes.NewReader(content), ids)
+	}
+	return decryptSops(sopsFormat(path), content, ids)
+}
+
+func decryptAge(r io.Reader, ids []age.Identity) ([]byte, error) {
+	plaintext, err := age.Decrypt(r, ids...)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(plaintext)
+}
+
+// ageIdentities reads the identities from the given keys,
+// or from where SOPS looks for them if none are given.
+// The given key file is read through the loader, while those
+// SOPS would read belong to the machine, and are read directly.
+func (kvl *loader) ageIdentities(keys *types.AgeKeys) ([]age.Identity, error) {
+	if keys != nil && (keys.Env != "" || keys.File != "") {
+		var ids []age.Identity
+		if keys.Env != "" {
+			v := os.Getenv(keys.Env)
+			if v == "" {
+				return nil, fmt.Errorf(
+					"environment variable %s holds no age keys", keys.Env)
+			}
+			more, err := age.ParseIdentities(strings.NewReader(v))
+			if err != nil {
+				return nil, errors.Wrapf(
+					err, "age keys in environment variable %s", keys.Env)
+			}
+			ids = append(ids, more...)
+		}
+		if keys.File != "" {
+			content, err := kvl.ldr.Load(keys.File)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading age keys")
+			}
+			more, err := parseAgeKeys(content, keys.File)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, more...)
+		}
+		return ids, nil
+	}
+	if v := os.Getenv(sopsAgeKeyEnv); v != "" {
+		ids, err := age.ParseIdentities(strings.NewReader(v))
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "age keys in environment variable %s", sopsAgeKeyEnv)
+		}
+		return ids, nil
+	}
+	if path := os.Getenv(sopsAgeKeyFileEnv); path != "" {
+		return readAgeKeyFile(path)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		path := filepath.Join(dir, "sops", "age", "keys.txt")
+		if _, err := os.Stat(path); err == nil {
+			return readAgeKeyFile(path)
+		}
+	}
+	return nil, fmt.Errorf(
+		"no age keys found; set ageKeys, or the environment variable %s or %s",
+		sopsAgeKeyEnv, sopsAgeKeyFileEnv)
+}
+
+func readAgeKeyFile(path string) ([]age.Identity, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading age keys")
+	}
+	return parseAgeKeys(content, path)
+}
+
+func parseAgeKeys(content []byte, path string) ([]age.Identity, error) {
+	ids, err := age.ParseIdentities(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "age keys in %s", path)
+	}
+	return ids, nil
+}
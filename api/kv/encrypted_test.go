@@ -0,0 +1,212 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// sopsTestdata holds files encrypted by the sops and age
+// commands for the key in keys.txt; see its README.md.
+const sopsTestdata = "testdata/sops"
+
+// setEnv sets the environment variable for the duration of the test.
+func setEnv(t *testing.T, k, v string) {
+	t.Helper()
+	old, had := os.LookupEnv(k)
+	if err := os.Setenv(k, v); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(k, old)
+		} else {
+			os.Unsetenv(k)
+		}
+	})
+}
+
+// readTestdata reads the files under the directory.
+func readTestdata(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, info := range infos {
+		content, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[info.Name()] = string(content)
+	}
+	return files
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	files := readTestdata(t, sopsTestdata)
+	key := files["keys.txt"]
+	otherID, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["tampered.yaml"] = strings.Replace(files["db.yaml"],
+		"replicas_unencrypted: 3", "replicas_unencrypted: 4", 1)
+	keyDir, err := ioutil.TempDir("", "agekeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(keyDir)
+	keyFile := filepath.Join(keyDir, "sops", "age", "keys.txt")
+	if err = os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(keyFile, []byte(key), 0600); err != nil {
+		t.Fatal(err)
+	}
+	recipient := "age1tl9tasd379c8m3z450mg5g2c4dwg4kg0e9eqhxmfvua73lfzfs5qnnch9h"
+
+	testCases := map[string]struct {
+		args     types.EncryptedSources
+		env      map[string]string
+		expected []types.Pair
+		err      string
+	}{
+		"sops yaml": {
+			args: types.EncryptedSources{FileSources: []string{"db.yaml"}},
+			env:  map[string]string{sopsAgeKeyEnv: key},
+			expected: []types.Pair{{Key: "db.yaml", Value: `db:
+  password: s3cret
+  # the port
+  port: 5432
+replicas_unencrypted: 3
+tls: true
+`}},
+		},
+		"sops yaml with encrypted regex": {
+			args: types.EncryptedSources{FileSources: []string{"regex.yaml"}},
+			env:  map[string]string{sopsAgeKeyEnv: key},
+			expected: []types.Pair{{Key: "regex.yaml", Value: `user: admin
+password: s3cret
+`}},
+		},
+		"sops json": {
+			args: types.EncryptedSources{FileSources: []string{"users=users.json"}},
+			env:  map[string]string{sopsAgeKeyEnv: key},
+			expected: []types.Pair{{Key: "users", Value: `{
+  "user": "admin",
+  "ids": [
+    1,
+    2
+  ]
+}
+`}},
+		},
+		"sops binary": {
+			args: types.EncryptedSources{FileSources: []string{"tls.crt"}},
+			env:  map[string]string{sopsAgeKeyFileEnv: keyFile},
+			expected: []types.Pair{{Key: "tls.crt",
+				Value: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"}},
+		},
+		"sops dotenv and age env": {
+			args: types.EncryptedSources{
+				EnvSources: []string{"app.env", "age.env"},
+				AgeKeys:    &types.AgeKeys{File: "keys.txt"},
+			},
+			expected: []types.Pair{
+				{Key: "USER", Value: "admin"},
+				{Key: "PASSWORD", Value: "s3cret"},
+				{Key: "TOKEN", Value: "abc"},
+			},
+		},
+		"keys from the user config directory": {
+			args:     types.EncryptedSources{EnvSources: []string{"age.env"}},
+			env:      map[string]string{"XDG_CONFIG_HOME": keyDir},
+			expected: []types.Pair{{Key: "TOKEN", Value: "abc"}},
+		},
+		"keys from a configured variable": {
+			args: types.EncryptedSources{
+				EnvSources: []string{"age.env"},
+				AgeKeys:    &types.AgeKeys{Env: "MY_AGE_KEYS"},
+			},
+			env:      map[string]string{"MY_AGE_KEYS": key},
+			expected: []types.Pair{{Key: "TOKEN", Value: "abc"}},
+		},
+		"empty configured variable": {
+			args: types.EncryptedSources{
+				EnvSources: []string{"age.env"},
+				AgeKeys:    &types.AgeKeys{Env: "MY_AGE_KEYS"},
+			},
+			err: "environment variable MY_AGE_KEYS holds no age keys",
+		},
+		"configured key file outside the root": {
+			args: types.EncryptedSources{
+				EnvSources: []string{"age.env"},
+				AgeKeys:    &types.AgeKeys{File: keyFile},
+			},
+			err: "reading age keys",
+		},
+		"no keys": {
+			args: types.EncryptedSources{EnvSources: []string{"age.env"}},
+			err:  "no age keys found",
+		},
+		"wrong age key": {
+			args: types.EncryptedSources{EnvSources: []string{"age.env"}},
+			env:  map[string]string{sopsAgeKeyEnv: otherID.String()},
+			err:  "no identity matched any of the recipients",
+		},
+		"wrong sops key": {
+			args: types.EncryptedSources{FileSources: []string{"db.yaml"}},
+			env:  map[string]string{sopsAgeKeyEnv: otherID.String()},
+			err:  "none of the age keys matches the recipients [" + recipient + "]",
+		},
+		"tampered": {
+			args: types.EncryptedSources{FileSources: []string{"tampered.yaml"}},
+			env:  map[string]string{sopsAgeKeyEnv: key},
+			err:  "the SOPS mac doesn't match the file",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			setEnv(t, sopsAgeKeyEnv, "")
+			setEnv(t, sopsAgeKeyFileEnv, "")
+			setEnv(t, "MY_AGE_KEYS", "")
+			// The user config directory holds no keys but for
+			// the test saying so.
+			setEnv(t, "XDG_CONFIG_HOME", filepath.Join(keyDir, "none"))
+			for k, v := range tc.env {
+				setEnv(t, k, v)
+			}
+			fSys := filesys.MakeFsInMemory()
+			for f, content := range files {
+				if err := fSys.WriteFile(f, []byte(content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			pairs, err := makeKvLoader(fSys).LoadEncrypted(tc.args)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(pairs, tc.expected) {
+				t.Fatalf("expected %q, got %q", tc.expected, pairs)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// keyValuesFromEnvVarSources reads the environment
+// variables named by the sources, e.g. NAME or key=NAME.
+func (kvl *loader) keyValuesFromEnvVarSources(
+	sources []string) ([]types.Pair, error) {
+	if len(sources) > 0 && !kvl.external.Enabled {
+		return nil, fmt.Errorf(
+			"env var sources %v require --enable-external-sources", sources)
+	}
+	var kvs []types.Pair
+	for _, s := range sources {
+		k, name, err := parseEnvVarSource(s)
+		if err != nil {
+			return nil, err
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		kvs = append(kvs, types.Pair{Key: k, Value: v})
+	}
+	return kvs, nil
+}
+
+func parseEnvVarSource(source string) (keyName, name string, err error) {
+	items := strings.Split(source, "=")
+	switch {
+	case len(items) == 1 && source != "":
+		return source, source, nil
+	case len(items) == 2 && items[0] != "" && items[1] != "":
+		return items[0], items[1], nil
+	default:
+		return "", "", fmt.Errorf(
+			"invalid env var source %q, expected NAME or key=NAME", source)
+	}
+}
+
+// keyValuesFromCommandSources runs the commands in
+// the root of the loader, one at a time.
+func (kvl *loader) keyValuesFromCommandSources(
+	sources []types.CommandSource) ([]types.Pair, error) {
+	if len(sources) > 0 && !kvl.external.Enabled {
+		return nil, fmt.Errorf(
+			"command sources require --enable-external-sources")
+	}
+	var kvs []types.Pair
+	for _, s := range sources {
+		v, err := kvl.runCommandSource(s)
+		if err != nil {
+			return nil, fmt.Errorf("command source %s: %v", s.Key, err)
+		}
+		kvs = append(kvs, types.Pair{Key: s.Key, Value: v})
+	}
+	return kvs, nil
+}
+
+func (kvl *loader) runCommandSource(s types.CommandSource) (string, error) {
+	if s.Key == "" {
+		return "", fmt.Errorf("missing key")
+	}
+	if len(s.Command) == 0 || s.Command[0] == "" {
+		return "", fmt.Errorf("missing command")
+	}
+	timeout := types.DefaultCommandSourceTimeout
+	if s.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(s.Timeout)
+		if err != nil {
+			return "", fmt.Errorf("invalid timeout: %v", err)
+		}
+		if timeout <= 0 {
+			return "", fmt.Errorf("timeout %s must be positive", s.Timeout)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...)
+	cmd.Dir = kvl.ldr.Root()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("%q timed out after %v", s.Command, timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%q failed: %v: %s",
+			s.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	if s.TrimSpace {
+		return strings.TrimSpace(stdout.String()), nil
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	ldr "sigs.k8s.io/kustomize/api/loader"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func makeExternalKvLoader(t *testing.T, enabled bool) *loader {
+	t.Helper()
+	fl, err := ldr.NewLoader(
+		ldr.RestrictionRootOnly, t.TempDir(), filesys.MakeFsOnDisk())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &loader{
+		ldr:       fl,
+		validator: valtest_test.MakeFakeValidator(),
+		external:  types.ExternalSourcesConfig{Enabled: enabled},
+	}
+}
+
+func TestKeyValuesFromEnvVarSources(t *testing.T) {
+	setEnv(t, "KUSTOMIZE_EXTERNAL_A", "a value")
+	setEnv(t, "KUSTOMIZE_EXTERNAL_EMPTY", "")
+	kvl := makeExternalKvLoader(t, true)
+	pairs, err := kvl.keyValuesFromEnvVarSources([]string{
+		"KUSTOMIZE_EXTERNAL_A",
+		"a=KUSTOMIZE_EXTERNAL_A",
+		"KUSTOMIZE_EXTERNAL_EMPTY",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []types.Pair{
+		{Key: "KUSTOMIZE_EXTERNAL_A", Value: "a value"},
+		{Key: "a", Value: "a value"},
+		{Key: "KUSTOMIZE_EXTERNAL_EMPTY", Value: ""},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("expected %q, got %q", expected, pairs)
+	}
+
+	for source, msg := range map[string]string{
+		"KUSTOMIZE_EXTERNAL_UNSET": "environment variable KUSTOMIZE_EXTERNAL_UNSET is not set",
+		"":                         `invalid env var source "", expected NAME or key=NAME`,
+		"=A":                       `invalid env var source "=A", expected NAME or key=NAME`,
+		"a=":                       `invalid env var source "a=", expected NAME or key=NAME`,
+		"a=b=c":                    `invalid env var source "a=b=c", expected NAME or key=NAME`,
+	} {
+		_, err = kvl.keyValuesFromEnvVarSources([]string{source})
+		if err == nil || err.Error() != msg {
+			t.Fatalf("source %q: expected error %q, got %v", source, msg, err)
+		}
+	}
+
+	kvl = makeExternalKvLoader(t, false)
+	_, err = kvl.keyValuesFromEnvVarSources([]string{"KUSTOMIZE_EXTERNAL_A"})
+	if err == nil || !strings.Contains(err.Error(),
+		"require --enable-external-sources") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestKeyValuesFromCommandSources(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("needs sh")
+	}
+	kvl := makeExternalKvLoader(t, true)
+	pairs, err := kvl.keyValuesFromCommandSources([]types.CommandSource{
+		{Key: "raw", Command: []string{"echo", "v1.2.3"}},
+		{Key: "trimmed", Command: []string{"echo", " v1.2.3 "}, TrimSpace: true},
+		{Key: "cwd", Command: []string{"sh", "-c", "pwd"}, TrimSpace: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []types.Pair{
+		{Key: "raw", Value: "v1.2.3\n"},
+		{Key: "trimmed", Value: "v1.2.3"},
+		{Key: "cwd", Value: kvl.ldr.Root()},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("expected %q, got %q", expected, pairs)
+	}
+
+	testCases := map[string]struct {
+		source types.CommandSource
+		err    string
+	}{
+		"missing key": {
+			source: types.CommandSource{Command: []string{"true"}},
+			err:    "command source : missing key",
+		},
+		"missing command": {
+			source: types.CommandSource{Key: "k"},
+			err:    "command source k: missing command",
+		},
+		"failure": {
+			source: types.CommandSource{
+				Key: "k", Command: []string{"sh", "-c", "echo oops >&2; exit 3"}},
+			err: `command source k: ["sh" "-c" "echo oops >&2; exit 3"] failed: ` +
+				`exit status 3: oops`,
+		},
+		"timeout": {
+			source: types.CommandSource{
+				Key: "k", Command: []string{"sleep", "5"}, Timeout: "50ms"},
+			err: `command source k: ["sleep" "5"] timed out after 50ms`,
+		},
+		"invalid timeout": {
+			source: types.CommandSource{
+				Key: "k", Command: []string{"true"}, Timeout: "soon"},
+			err: "command source k: invalid timeout: ",
+		},
+		"negative timeout": {
+			source: types.CommandSource{
+				Key: "k", Command: []string{"true"}, Timeout: "-1s"},
+			err: "command source k: timeout -1s must be positive",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := kvl.keyValuesFromCommandSources(
+				[]types.CommandSource{tc.source})
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+
+	kvl = makeExternalKvLoader(t, false)
+	_, err = kvl.keyValuesFromCommandSources([]types.CommandSource{
+		{Key: "k", Command: []string{"true"}}})
+	if err == nil || err.Error() !=
+		"command sources require --enable-external-sources" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
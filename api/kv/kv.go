@@ -27,19 +27,32 @@ type loader struct {
 
 	// Used to validate various k8s data fields.
 	validator ifc.Validator
+
+	// Whether environment variable and command
+	// sources may be read.
+	external types.ExternalSourcesConfig
 }
 
 func NewLoader(ldr ifc.Loader, v ifc.Validator) ifc.KvLoader {
 	return &loader{ldr: ldr, validator: v}
 }
 
+// NewLoaderWithExternalSources returns a loader that, if the
+// config enables them, also reads environment variable and
+// command sources.
+func NewLoaderWithExternalSources(
+	ldr ifc.Loader, v ifc.Validator,
+	c types.ExternalSourcesConfig) ifc.KvLoader {
+	return &loader{ldr: ldr, validator: v, external: c}
+}
+
 func (kvl *loader) Validator() ifc.Validator {
 	return kvl.validator
 }
 
 func (kvl *loader) Load(
 	args types.KvPairSources) (all []types.Pair, err error) {
-	pairs, err := kvl.keyValuesFromEnvFiles(args.EnvSources)
+	pairs, err := kvl.keyValuesFromEnvSources(args)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"env source files: %v",
@@ -59,6 +72,30 @@ func (kvl *loader) Load(
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"file sources: %v", args.FileSources))
 	}
+	all = append(all, pairs...)
+
+	pairs, err = kvl.keyValuesFromStructuredSources(args.StructuredSources)
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, pairs...)
+
+	pairs, err = kvl.keyValuesFromDirectorySources(args.DirectorySources)
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, pairs...)
+
+	pairs, err = kvl.keyValuesFromEnvVarSources(args.EnvVarSources)
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, pairs...)
+
+	pairs, err = kvl.keyValuesFromCommandSources(args.CommandSources)
+	if err != nil {
+		return nil, err
+	}
 	return append(all, pairs...), nil
 }
 
@@ -90,6 +127,24 @@ func (kvl *loader) keyValuesFromFileSources(sources []string) ([]types.Pair, err
 	return kvs, nil
 }
 
+// keyValuesFromEnvSources reads the env sources in their format.
+func (kvl *loader) keyValuesFromEnvSources(
+	args types.KvPairSources) ([]types.Pair, error) {
+	switch args.EnvFormat {
+	case "":
+		if args.ExpandEnvVars {
+			return nil, fmt.Errorf(
+				"expandEnvVars requires the %s envFormat", types.EnvFormatDotenv)
+		}
+		return kvl.keyValuesFromEnvFiles(args.EnvSources)
+	case types.EnvFormatDotenv:
+		return kvl.keyValuesFromDotenvFiles(args.EnvSources, args.ExpandEnvVars)
+	default:
+		return nil, fmt.Errorf("unknown envFormat '%s'; must be empty or %s",
+			args.EnvFormat, types.EnvFormatDotenv)
+	}
+}
+
 func (kvl *loader) keyValuesFromEnvFiles(paths []string) ([]types.Pair, error) {
 	var kvs []types.Pair
 	for _, p := range paths {
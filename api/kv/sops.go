@@ -0,0 +1,587 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// The formats of SOPS files.
+const (
+	sopsFormatYAML   = "yaml"
+	sopsFormatJSON   = "json"
+	sopsFormatDotenv = "dotenv"
+	sopsFormatBinary = "binary"
+)
+
+// sopsMetadataKey holds the metadata of SOPS files.
+const sopsMetadataKey = "sops"
+
+// sopsValue matches a value encrypted by SOPS.
+var sopsValue = regexp.MustCompile(
+	`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// sopsMetadata holds the parts of a SOPS file's metadata
+// needed to decrypt it with age keys.
+type sopsMetadata struct {
+	Age               []sopsAgeKey  `yaml:"age"`
+	KeyGroups         []interface{} `yaml:"key_groups"`
+	LastModified      string        `yaml:"lastmodified"`
+	MAC               string        `yaml:"mac"`
+	MACOnlyEncrypted  bool          `yaml:"mac_only_encrypted"`
+	UnencryptedSuffix string        `yaml:"unencrypted_suffix"`
+	EncryptedSuffix   string        `yaml:"encrypted_suffix"`
+	UnencryptedRegex  string        `yaml:"unencrypted_regex"`
+	EncryptedRegex    string        `yaml:"encrypted_regex"`
+}
+
+// sopsAgeKey is the data key of a SOPS file, encrypted
+// with age for the recipient.
+type sopsAgeKey struct {
+	Recipient string `yaml:"recipient"`
+	Enc       string `yaml:"enc"`
+}
+
+// sopsFormat returns the format of the SOPS file at
+// the path, telling it from the extension as SOPS does.
+func sopsFormat(path string) string {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return sopsFormatYAML
+	case ".json":
+		return sopsFormatJSON
+	case ".env":
+		return sopsFormatDotenv
+	default:
+		return sopsFormatBinary
+	}
+}
+
+// decryptSops returns the plaintext of a SOPS file, in its
+// format and without its metadata, after checking its MAC.
+func decryptSops(
+	format string, content []byte, ids []age.Identity) ([]byte, error) {
+	if format == sopsFormatDotenv {
+		return decryptSopsDotenv(content, ids)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 ||
+		doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("not a SOPS file")
+	}
+	root := doc.Content[0]
+	var metaNode *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == sopsMetadataKey {
+			metaNode = root.Content[i+1]
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			break
+		}
+	}
+	if metaNode == nil {
+		return nil, fmt.Errorf(
+			"not a SOPS file; it has no '%s' key", sopsMetadataKey)
+	}
+	var meta sopsMetadata
+	if err := metaNode.Decode(&meta); err != nil {
+		return nil, errors.Wrap(err, "SOPS metadata")
+	}
+	d, err := newSopsDecrypter(meta, ids)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.decryptNode(root, nil); err != nil {
+		return nil, err
+	}
+	if err = d.verifyMAC(); err != nil {
+		return nil, err
+	}
+	switch format {
+	case sopsFormatBinary:
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "data" {
+				return []byte(root.Content[i+1].Value), nil
+			}
+		}
+		return nil, errors.New("SOPS binary file has no 'data' key")
+	case sopsFormatJSON:
+		var b bytes.Buffer
+		if err = writeNodeAsJSON(&b, root); err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err = json.Indent(&out, b.Bytes(), "", "  "); err != nil {
+			return nil, err
+		}
+		out.WriteString("\n")
+		return out.Bytes(), nil
+	default:
+		var out bytes.Buffer
+		e := yaml.NewEncoder(&out)
+		if err = e.Encode(&doc); err != nil {
+			return nil, err
+		}
+		if err = e.Close(); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	}
+}
+
+// decryptSopsDotenv decrypts a SOPS dotenv file, whose
+// metadata is held by the keys prefixed with "sops_".
+func decryptSopsDotenv(content []byte, ids []age.Identity) ([]byte, error) {
+	type item struct {
+		key, value string
+		comment    bool
+	}
+	var items []item
+	metaPairs := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] == '#' {
+			items = append(items, item{value: line[1:], comment: true})
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid dotenv line %q", line)
+		}
+		k, v := line[:i], strings.ReplaceAll(line[i+1:], `\n`, "\n")
+		if strings.HasPrefix(k, sopsMetadataKey+"_") {
+			metaPairs[strings.TrimPrefix(k, sopsMetadataKey+"_")] = v
+			continue
+		}
+		items = append(items, item{key: k, value: v})
+	}
+	if len(metaPairs) == 0 {
+		return nil, fmt.Errorf(
+			"not a SOPS file; it has no '%s_' keys", sopsMetadataKey)
+	}
+	meta, err := sopsMetadataFromPairs(metaPairs)
+	if err != nil {
+		return nil, err
+	}
+	d, err := newSopsDecrypter(meta, ids)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	for _, it := range items {
+		if it.comment {
+			out.WriteString("#" + d.comment(it.value, nil) + "\n")
+			continue
+		}
+		v, _, err := d.leaf(it.value, "str", []string{it.key})
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(it.key + "=" + strings.ReplaceAll(v, "\n", `\n`) + "\n")
+	}
+	if err = d.verifyMAC(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// sopsMetadataFromPairs reads metadata flattened into dotenv
+// pairs, e.g. age__list_0__map_recipient.
+func sopsMetadataFromPairs(pairs map[string]string) (sopsMetadata, error) {
+	var meta sopsMetadata
+	ageKeys := make(map[int]*sopsAgeKey)
+	for k, v := range pairs {
+		switch k {
+		case "lastmodified":
+			meta.LastModified = v
+		case "mac":
+			meta.MAC = v
+		case "mac_only_encrypted":
+			meta.MACOnlyEncrypted = v == "true"
+		case "unencrypted_suffix":
+			meta.UnencryptedSuffix = v
+		case "encrypted_suffix":
+			meta.EncryptedSuffix = v
+		case "unencrypted_regex":
+			meta.UnencryptedRegex = v
+		case "encrypted_regex":
+			meta.EncryptedRegex = v
+		}
+		if strings.HasPrefix(k, "key_groups") {
+			meta.KeyGroups = append(meta.KeyGroups, v)
+		}
+		var i int
+		var field string
+		if n, _ := fmt.Sscanf(k, "age__list_%d__map_%s", &i, &field); n == 2 {
+			if ageKeys[i] == nil {
+				ageKeys[i] = &sopsAgeKey{}
+			}
+			switch field {
+			case "recipient":
+				ageKeys[i].Recipient = v
+			case "enc":
+				ageKeys[i].Enc = v
+			}
+		}
+	}
+	var indices []int
+	for i := range ageKeys {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	for _, i := range indices {
+		meta.Age = append(meta.Age, *ageKeys[i])
+	}
+	return meta, nil
+}
+
+// sopsDecrypter decrypts the values of a SOPS file,
+// and computes its MAC as it goes.
+type sopsDecrypter struct {
+	meta             sopsMetadata
+	dataKey          []byte
+	unencryptedRegex *regexp.Regexp
+	encryptedRegex   *regexp.Regexp
+	hash             hash.Hash
+}
+
+func newSopsDecrypter(
+	meta sopsMetadata, ids []age.Identity) (*sopsDecrypter, error) {
+	if len(meta.KeyGroups) > 0 {
+		return nil, errors.New("SOPS key groups aren't supported")
+	}
+	d := &sopsDecrypter{meta: meta, hash: sha512.New()}
+	var err error
+	if meta.UnencryptedRegex != "" {
+		if d.unencryptedRegex, err = regexp.Compile(meta.UnencryptedRegex); err != nil {
+			return nil, errors.Wrap(err, "SOPS unencrypted_regex")
+		}
+	}
+	if meta.EncryptedRegex != "" {
+		if d.encryptedRegex, err = regexp.Compile(meta.EncryptedRegex); err != nil {
+			return nil, errors.Wrap(err, "SOPS encrypted_regex")
+		}
+	}
+	if d.dataKey, err = sopsDataKey(meta.Age, ids); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// sopsDataKey decrypts the data key of a SOPS file with the
+// first of its age recipients that one of the identities matches.
+func sopsDataKey(keys []sopsAgeKey, ids []age.Identity) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("the SOPS file has no age recipients")
+	}
+	var recipients []string
+	for _, k := range keys {
+		key, err := decryptAge(
+			armor.NewReader(strings.NewReader(strings.TrimSpace(k.Enc))), ids)
+		if err == nil {
+			return key, nil
+		}
+		recipients = append(recipients, k.Recipient)
+	}
+	return nil, fmt.Errorf(
+		"none of the age keys matches the recipients %v", recipients)
+}
+
+// encrypted tells whether the value at the path is encrypted.
+func (d *sopsDecrypter) encrypted(path []string) bool {
+	anyMatches := func(match func(string) bool) bool {
+		for _, p := range path {
+			if match(p) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case d.meta.UnencryptedSuffix != "":
+		return !anyMatches(func(p string) bool {
+			return strings.HasSuffix(p, d.meta.UnencryptedSuffix)
+		})
+	case d.meta.EncryptedSuffix != "":
+		return anyMatches(func(p string) bool {
+			return strings.HasSuffix(p, d.meta.EncryptedSuffix)
+		})
+	case d.unencryptedRegex != nil:
+		return !anyMatches(d.unencryptedRegex.MatchString)
+	case d.encryptedRegex != nil:
+		return anyMatches(d.encryptedRegex.MatchString)
+	}
+	return true
+}
+
+// decrypt returns the plaintext of the encrypted value,
+// authenticated with the additional data, and its type.
+func (d *sopsDecrypter) decrypt(value, aad string) (string, string, error) {
+	if value == "" {
+		return "", "str", nil
+	}
+	match := sopsValue.FindStringSubmatch(value)
+	if match == nil {
+		return "", "", errors.New("the value isn't encrypted")
+	}
+	var parts [3][]byte
+	for i := range parts {
+		var err error
+		if parts[i], err = base64.StdEncoding.DecodeString(match[i+1]); err != nil {
+			return "", "", errors.Wrap(err, "the value is malformed")
+		}
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+	block, err := aes.NewCipher(d.dataKey)
+	if err != nil {
+		return "", "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(aad))
+	if err != nil {
+		return "", "", errors.New(
+			"the value can't be decrypted; it may have been tampered with")
+	}
+	return string(plaintext), match[4], nil
+}
+
+// leaf returns the plaintext of the value at the path,
+// and its type, and adds it to the MAC.
+func (d *sopsDecrypter) leaf(
+	value, typ string, path []string) (string, string, error) {
+	encrypted := d.encrypted(path)
+	if encrypted {
+		var err error
+		value, typ, err = d.decrypt(value, strings.Join(path, ":")+":")
+		if err != nil {
+			return "", "", errors.Wrapf(err, "at %s", strings.Join(path, "."))
+		}
+	}
+	if encrypted || !d.meta.MACOnlyEncrypted {
+		if err := d.addToMAC(value, typ); err != nil {
+			return "", "", err
+		}
+	}
+	if encrypted && typ == "bool" {
+		// SOPS encrypts booleans capitalized, as Python prints them.
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "at %s", strings.Join(path, "."))
+		}
+		value = strconv.FormatBool(b)
+	}
+	return value, typ, nil
+}
+
+// comment returns the plaintext of the comment in the
+// branch at the path.  Comments written by old SOPS versions
+// aren't encrypted.  As in SOPS, comments aren't in the MAC.
+func (d *sopsDecrypter) comment(text string, path []string) string {
+	if d.encrypted(path) {
+		if plaintext, _, err := d.decrypt(
+			text, strings.Join(path, ":")+":"); err == nil {
+			text = plaintext
+		}
+	}
+	return text
+}
+
+// addToMAC adds the value to the MAC, in the
+// representation SOPS gives its type.
+func (d *sopsDecrypter) addToMAC(value, typ string) error {
+	switch typ {
+	case "int":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		value = strconv.Itoa(n)
+	case "float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		value = strconv.FormatFloat(f, 'f', -1, 64)
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		// SOPS capitalizes booleans, as Python does.
+		value = "False"
+		if b {
+			value = "True"
+		}
+	}
+	d.hash.Write([]byte(value))
+	return nil
+}
+
+// verifyMAC checks the MAC of the file against
+// the one computed from its values.
+func (d *sopsDecrypter) verifyMAC() error {
+	lastModified, err := time.Parse(time.RFC3339, d.meta.LastModified)
+	if err != nil {
+		return errors.Wrap(err, "SOPS lastmodified")
+	}
+	mac, _, err := d.decrypt(d.meta.MAC, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return errors.Wrap(err, "SOPS mac")
+	}
+	if mac != fmt.Sprintf("%X", d.hash.Sum(nil)) {
+		return errors.New(
+			"the SOPS mac doesn't match the file; it may have been tampered with")
+	}
+	return nil
+}
+
+// decryptNode decrypts the values and comments of the
+// node at the path in place.  Like SOPS, it doesn't add
+// list indices to paths.
+func (d *sopsDecrypter) decryptNode(n *yaml.Node, path []string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			d.decryptComments(&k.HeadComment, path)
+			d.decryptComments(&k.LineComment, path)
+			childPath := append(path[:len(path):len(path)], k.Value)
+			if err := d.decryptNode(v, childPath); err != nil {
+				return err
+			}
+			d.decryptComments(&k.FootComment, path)
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			d.decryptComments(&item.HeadComment, path)
+			if err := d.decryptNode(item, path); err != nil {
+				return err
+			}
+			d.decryptComments(&item.LineComment, path)
+			d.decryptComments(&item.FootComment, path)
+		}
+	case yaml.ScalarNode:
+		value, typ, err := d.leaf(n.Value, sopsType(n), path)
+		if err != nil {
+			return err
+		}
+		n.Value, n.Tag, n.Style = value, yamlTag(typ), 0
+	}
+	return nil
+}
+
+// decryptComments decrypts each line of the comment.
+func (d *sopsDecrypter) decryptComments(comment *string, path []string) {
+	if *comment == "" {
+		return
+	}
+	lines := strings.Split(*comment, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			lines[i] = "#" + d.comment(line[1:], path)
+		}
+	}
+	*comment = strings.Join(lines, "\n")
+}
+
+// sopsType returns the SOPS type of an unencrypted scalar.
+func sopsType(n *yaml.Node) string {
+	switch n.ShortTag() {
+	case yaml.NodeTagInt:
+		return "int"
+	case yaml.NodeTagFloat:
+		return "float"
+	case yaml.NodeTagBool:
+		return "bool"
+	}
+	return "str"
+}
+
+func yamlTag(typ string) string {
+	switch typ {
+	case "int":
+		return yaml.NodeTagInt
+	case "float":
+		return yaml.NodeTagFloat
+	case "bool":
+		return yaml.NodeTagBool
+	}
+	return yaml.NodeTagString
+}
+
+// writeNodeAsJSON writes the node as compact JSON,
+// keeping the order of its keys.
+func writeNodeAsJSON(b *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		b.WriteString("{")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			k, err := json.Marshal(n.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			b.Write(k)
+			b.WriteString(":")
+			if err = writeNodeAsJSON(b, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		b.WriteString("}")
+	case yaml.SequenceNode:
+		b.WriteString("[")
+		for i, item := range n.Content {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			if err := writeNodeAsJSON(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteString("]")
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case yaml.NodeTagInt, yaml.NodeTagFloat, yaml.NodeTagBool:
+			b.WriteString(n.Value)
+		case yaml.NodeTagNull:
+			b.WriteString("null")
+		default:
+			v, err := json.Marshal(n.Value)
+			if err != nil {
+				return err
+			}
+			b.Write(v)
+		}
+	default:
+		return fmt.Errorf("can't write YAML node of kind %v as JSON", n.Kind)
+	}
+	return nil
+}
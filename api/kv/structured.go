@@ -0,0 +1,426 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultKeySeparator = "."
+
+func (kvl *loader) keyValuesFromStructuredSources(
+	sources []types.StructuredSource) ([]types.Pair, error) {
+	var kvs []types.Pair
+	for _, s := range sources {
+		more, err := kvl.keyValuesFromStructuredSource(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "structured source %s", s.Path)
+		}
+		kvs = append(kvs, more...)
+	}
+	return kvs, nil
+}
+
+// keyValuesFromStructuredSource returns a pair for each leaf value
+// of the source, or each of its selected subtrees.
+func (kvl *loader) keyValuesFromStructuredSource(
+	s types.StructuredSource) ([]types.Pair, error) {
+	format, err := structuredFormat(s)
+	if err != nil {
+		return nil, err
+	}
+	content, err := kvl.ldr.Load(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := parseStructured(format, content)
+	if err != nil {
+		return nil, err
+	}
+	sep := s.KeySeparator
+	if sep == "" {
+		sep = defaultKeySeparator
+	}
+	var kvs []types.Pair
+	if len(s.Subtrees) == 0 {
+		if format == types.StructuredFormatProperties {
+			// the keys are already flat
+			sep = defaultKeySeparator
+		}
+		kvs = flattenStructured("", sep, tree, kvs)
+	}
+	for _, st := range s.Subtrees {
+		value, found := lookupStructured(format, tree, st.Path, sep)
+		if !found {
+			return nil, fmt.Errorf("no value at path '%s'", st.Path)
+		}
+		serialized, err := serializeStructured(format, value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value at path '%s'", st.Path)
+		}
+		key := st.Key
+		if key == "" {
+			key = st.Path
+		}
+		kvs = append(kvs, types.Pair{Key: key, Value: serialized})
+	}
+	for _, kv := range kvs {
+		if err := kvl.validator.ErrIfInvalidKey(kv.Key); err != nil {
+			return nil, errors.Wrapf(err, "invalid key '%s'", kv.Key)
+		}
+	}
+	return kvs, nil
+}
+
+// structuredFormat returns the format of the source,
+// by default the one its extension names.
+func structuredFormat(s types.StructuredSource) (string, error) {
+	format := s.Format
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(s.Path)) {
+		case ".json":
+			format = types.StructuredFormatJSON
+		case ".yaml", ".yml":
+			format = types.StructuredFormatYAML
+		case ".toml":
+			format = types.StructuredFormatTOML
+		case ".properties":
+			format = types.StructuredFormatProperties
+		default:
+			return "", fmt.Errorf(
+				"can't tell the format of '%s' from its extension; set the format",
+				s.Path)
+		}
+	}
+	switch format {
+	case types.StructuredFormatJSON, types.StructuredFormatYAML,
+		types.StructuredFormatTOML, types.StructuredFormatProperties:
+		return format, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown format '%s'; must be one of %s, %s, %s or %s", format,
+			types.StructuredFormatJSON, types.StructuredFormatYAML,
+			types.StructuredFormatTOML, types.StructuredFormatProperties)
+	}
+}
+
+// parseStructured returns the content as maps, lists and scalars.
+// The values of properties are a map from each key to its value.
+func parseStructured(format string, content []byte) (interface{}, error) {
+	var tree interface{}
+	switch format {
+	case types.StructuredFormatJSON, types.StructuredFormatYAML:
+		j, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(j))
+		// keep numbers as written
+		dec.UseNumber()
+		if err = dec.Decode(&tree); err != nil {
+			return nil, err
+		}
+	case types.StructuredFormatTOML:
+		var m map[string]interface{}
+		if _, err := toml.Decode(string(content), &m); err != nil {
+			return nil, err
+		}
+		tree = normalizeTOML(m)
+	case types.StructuredFormatProperties:
+		m, err := parseProperties(content)
+		if err != nil {
+			return nil, err
+		}
+		tree = m
+	}
+	switch tree.(type) {
+	case map[string]interface{}, []interface{}:
+		return tree, nil
+	default:
+		return nil, fmt.Errorf("must hold a map or a list")
+	}
+}
+
+// normalizeTOML turns the arrays of tables of
+// the decoded TOML into ordinary lists.
+func normalizeTOML(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, e := range x {
+			x[k] = normalizeTOML(e)
+		}
+		return x
+	case []map[string]interface{}:
+		result := make([]interface{}, len(x))
+		for i, e := range x {
+			result[i] = normalizeTOML(e)
+		}
+		return result
+	case []interface{}:
+		for i, e := range x {
+			x[i] = normalizeTOML(e)
+		}
+		return x
+	default:
+		return v
+	}
+}
+
+// flattenStructured appends a pair for each leaf of the value to kvs,
+// keyed by the prefix and the path to the leaf joined by sep.
+// Empty maps and lists have no leaves.
+func flattenStructured(
+	prefix, sep string, v interface{}, kvs []types.Pair) []types.Pair {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + sep + k
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kvs = flattenStructured(join(k), sep, x[k], kvs)
+		}
+	case []interface{}:
+		for i, e := range x {
+			kvs = flattenStructured(join(strconv.Itoa(i)), sep, e, kvs)
+		}
+	default:
+		kvs = append(kvs, types.Pair{Key: prefix, Value: formatScalar(v)})
+	}
+	return kvs
+}
+
+// lookupStructured returns the value at the path.  In properties,
+// the path is a key, or the prefix of keys, which are then returned
+// as a map with the prefix removed from them.
+func lookupStructured(
+	format string, tree interface{}, path, sep string) (interface{}, bool) {
+	if format == types.StructuredFormatProperties {
+		m := tree.(map[string]interface{})
+		if v, found := m[path]; found {
+			return v, true
+		}
+		sub := make(map[string]interface{})
+		for k, v := range m {
+			if strings.HasPrefix(k, path+defaultKeySeparator) {
+				sub[strings.TrimPrefix(k, path+defaultKeySeparator)] = v
+			}
+		}
+		return sub, len(sub) > 0
+	}
+	v := tree
+	for _, segment := range strings.Split(path, sep) {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			var found bool
+			if v, found = x[segment]; !found {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, false
+			}
+			v = x[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// serializeStructured returns the value in the format, or,
+// if it's a scalar, as it's written in key value pairs.
+func serializeStructured(format string, v interface{}) (string, error) {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return formatScalar(v), nil
+	}
+	switch format {
+	case types.StructuredFormatJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(b) + "\n", nil
+	case types.StructuredFormatYAML:
+		b, err := yaml.Marshal(v)
+		return string(b), err
+	case types.StructuredFormatTOML:
+		if _, isMap := v.(map[string]interface{}); !isMap {
+			return "", fmt.Errorf("only tables can be serialized as TOML")
+		}
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	default:
+		return formatProperties(v.(map[string]interface{})), nil
+	}
+}
+
+// formatScalar returns the scalar as written in key value pairs.
+func formatScalar(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// parseProperties parses a Java properties file, as specified by
+// https://docs.oracle.com/javase/8/docs/api/java/util/Properties.html#load-java.io.Reader-
+func parseProperties(content []byte) (map[string]interface{}, error) {
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("has invalid utf8 bytes")
+	}
+	result := make(map[string]interface{})
+	lines := strings.Split(
+		strings.TrimPrefix(string(content), string(utf8bom)), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(strings.TrimSuffix(lines[i], "\r"), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// join continued lines
+		for endsWithEscape(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] +
+				strings.TrimLeft(strings.TrimSuffix(lines[i], "\r"), " \t\f")
+		}
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// endsWithEscape returns true if the line ends with
+// an odd number of backslashes, so it continues.
+func endsWithEscape(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty returns the unescaped key and value of the line.
+// The key ends at the first unescaped '=', ':' or white space.
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.ContainsRune("=: \t\f", rune(line[i])) {
+			end = i
+			break
+		}
+	}
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uxxxx escape in '%s'", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx escape in '%s'", s)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// formatProperties returns the properties, sorted by key.
+func formatProperties(m map[string]interface{}) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	escape := strings.NewReplacer(
+		`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\f", `\f`)
+	escapeKey := strings.NewReplacer(
+		`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\f", `\f`,
+		"=", `\=`, ":", `\:`, " ", `\ `)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(escapeKey.Replace(k))
+		b.WriteByte('=')
+		b.WriteString(escape.Replace(formatScalar(m[k])))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
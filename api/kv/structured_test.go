@@ -0,0 +1,235 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	ldr "sigs.k8s.io/kustomize/api/loader"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// keyValidator rejects keys which aren't valid ConfigMap keys.
+type keyValidator struct {
+	ifc.Validator
+}
+
+var validKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+func (v keyValidator) ErrIfInvalidKey(k string) error {
+	if !validKey.MatchString(k) {
+		return fmt.Errorf("a valid config key must consist of " +
+			"alphanumeric characters, '-', '_' or '.'")
+	}
+	return nil
+}
+
+const structuredYaml = `
+db:
+  host: localhost
+  port: 5432
+  ssl: true
+servers:
+- name: a
+  weight: 1.5
+- name: b
+empty: {}
+`
+
+func TestKeyValuesFromStructuredSources(t *testing.T) {
+	testCases := map[string]struct {
+		file     string
+		content  string
+		source   types.StructuredSource
+		expected []types.Pair
+		err      string
+	}{
+		"yaml leaves": {
+			file:    "config.yaml",
+			content: structuredYaml,
+			expected: []types.Pair{
+				{Key: "db.host", Value: "localhost"},
+				{Key: "db.port", Value: "5432"},
+				{Key: "db.ssl", Value: "true"},
+				{Key: "servers.0.name", Value: "a"},
+				{Key: "servers.0.weight", Value: "1.5"},
+				{Key: "servers.1.name", Value: "b"},
+			},
+		},
+		"key separator": {
+			file:    "config.yaml",
+			content: structuredYaml,
+			source:  types.StructuredSource{KeySeparator: "_"},
+			expected: []types.Pair{
+				{Key: "db_host", Value: "localhost"},
+				{Key: "db_port", Value: "5432"},
+				{Key: "db_ssl", Value: "true"},
+				{Key: "servers_0_name", Value: "a"},
+				{Key: "servers_0_weight", Value: "1.5"},
+				{Key: "servers_1_name", Value: "b"},
+			},
+		},
+		"invalid key": {
+			file:    "config.yaml",
+			content: structuredYaml,
+			source:  types.StructuredSource{KeySeparator: "/"},
+			err:     "invalid key 'db/host'",
+		},
+		"json with explicit format": {
+			file:    "config",
+			content: `{"a": {"b": [1, 2]}, "c": null, "big": 12345678901234567890}`,
+			source:  types.StructuredSource{Format: "json"},
+			expected: []types.Pair{
+				{Key: "a.b.0", Value: "1"},
+				{Key: "a.b.1", Value: "2"},
+				{Key: "big", Value: "12345678901234567890"},
+				{Key: "c", Value: ""},
+			},
+		},
+		"toml": {
+			file: "config.toml",
+			content: `
+title = "app"
+[db]
+host = "localhost"
+port = 5432
+[[servers]]
+name = "a"
+[[servers]]
+name = "b"
+`,
+			expected: []types.Pair{
+				{Key: "db.host", Value: "localhost"},
+				{Key: "db.port", Value: "5432"},
+				{Key: "servers.0.name", Value: "a"},
+				{Key: "servers.1.name", Value: "b"},
+				{Key: "title", Value: "app"},
+			},
+		},
+		"properties": {
+			file: "app.properties",
+			content: `
+# comment
+! another comment
+db.host = localhost
+db.port:5432
+greeting hello \
+    world
+path=c:\\temp\u0021
+`,
+			expected: []types.Pair{
+				{Key: "db.host", Value: "localhost"},
+				{Key: "db.port", Value: "5432"},
+				{Key: "greeting", Value: "hello world"},
+				{Key: "path", Value: `c:\temp!`},
+			},
+		},
+		"yaml subtrees": {
+			file:    "config.yaml",
+			content: structuredYaml,
+			source: types.StructuredSource{
+				Subtrees: []types.StructuredSubtree{
+					{Path: "db"},
+					{Path: "servers.1", Key: "second.yaml"},
+					{Path: "db.port", Key: "port"},
+				},
+			},
+			expected: []types.Pair{
+				{Key: "db", Value: "host: localhost\nport: 5432\nssl: true\n"},
+				{Key: "second.yaml", Value: "name: b\n"},
+				{Key: "port", Value: "5432"},
+			},
+		},
+		"json subtree": {
+			file:    "config.json",
+			content: `{"db": {"host": "localhost", "port": 5432}}`,
+			source: types.StructuredSource{
+				Subtrees: []types.StructuredSubtree{{Path: "db", Key: "db.json"}},
+			},
+			expected: []types.Pair{
+				{Key: "db.json", Value: "{\n  \"host\": \"localhost\",\n  \"port\": 5432\n}\n"},
+			},
+		},
+		"toml subtree": {
+			file:    "config.toml",
+			content: "[db]\nhost = \"localhost\"\nport = 5432\n",
+			source: types.StructuredSource{
+				Subtrees: []types.StructuredSubtree{{Path: "db", Key: "db.toml"}},
+			},
+			expected: []types.Pair{
+				{Key: "db.toml", Value: "host = \"localhost\"\nport = 5432\n"},
+			},
+		},
+		"properties subtree": {
+			file:    "app.properties",
+			content: "db.host=localhost\ndb.port=5432\nother=x\n",
+			source: types.StructuredSource{
+				Subtrees: []types.StructuredSubtree{{Path: "db", Key: "db.properties"}},
+			},
+			expected: []types.Pair{
+				{Key: "db.properties", Value: "host=localhost\nport=5432\n"},
+			},
+		},
+		"missing subtree": {
+			file:    "config.yaml",
+			content: structuredYaml,
+			source: types.StructuredSource{
+				Subtrees: []types.StructuredSubtree{{Path: "db.user"}},
+			},
+			err: "no value at path 'db.user'",
+		},
+		"unknown extension": {
+			file:    "config.ini",
+			content: "a=b",
+			err:     "can't tell the format of 'config.ini' from its extension",
+		},
+		"unknown format": {
+			file:    "config.ini",
+			content: "a=b",
+			source:  types.StructuredSource{Format: "ini"},
+			err:     "unknown format 'ini'",
+		},
+		"scalar document": {
+			file:    "config.yaml",
+			content: "hello",
+			err:     "must hold a map or a list",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			if err := fSys.WriteFile(tc.file, []byte(tc.content)); err != nil {
+				t.Fatal(err)
+			}
+			kvl := &loader{
+				ldr:       ldr.NewFileLoaderAtRoot(fSys),
+				validator: keyValidator{valtest_test.MakeFakeValidator()},
+			}
+			source := tc.source
+			source.Path = tc.file
+			pairs, err := kvl.Load(types.KvPairSources{
+				StructuredSources: []types.StructuredSource{source},
+			})
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(pairs, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, pairs)
+			}
+		})
+	}
+}
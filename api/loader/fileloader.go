@@ -9,7 +9,9 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -329,6 +331,87 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 	return fl.fSys.ReadFile(path)
 }
 
+var _ ifc.GlobLoader = &fileLoader{}
+
+// Glob returns the paths of the files matching the pattern,
+// or directly within the directory it names, sorted.
+// Relative patterns are taken relative to the root, as are
+// the returned paths.  It's an error if the load restrictor
+// disallows any of the files, or if nothing matches.
+func (fl *fileLoader) Glob(pattern string) ([]string, error) {
+	abs := pattern
+	if !filepath.IsAbs(abs) {
+		abs = fl.root.Join(abs)
+	}
+	if fl.fSys.IsDir(abs) {
+		abs = filepath.Join(abs, "*")
+	}
+	matches, err := fl.fSys.Glob(abs)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, m := range matches {
+		if fl.fSys.IsDir(m) {
+			continue
+		}
+		if _, err := fl.loadRestrictor(fl.fSys, fl.root, m); err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(pattern) {
+			m, err = filepath.Rel(fl.root.String(), m)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, m)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no files match '%s'", pattern)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+var _ ifc.TreeLoader = &fileLoader{}
+
+// Tree returns the sorted paths of the files in the tree under
+// the directory, relative to it and slash separated.  A relative
+// directory is taken relative to the root.  It's an error if the
+// load restrictor disallows any of the files.
+func (fl *fileLoader) Tree(dir string) ([]string, error) {
+	abs := dir
+	if !filepath.IsAbs(abs) {
+		abs = fl.root.Join(abs)
+	}
+	if !fl.fSys.IsDir(abs) {
+		return nil, fmt.Errorf("'%s' must be a directory", dir)
+	}
+	var result []string
+	err := fl.fSys.Walk(abs, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if _, err = fl.loadRestrictor(fl.fSys, fl.root, p); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(abs, p)
+		if err != nil {
+			return err
+		}
+		result = append(result, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
 // Cleanup runs the cleaner.
 func (fl *fileLoader) Cleanup() error {
 	return fl.cleaner()
@@ -206,13 +206,12 @@ const (
 
 // Create a structure like this
 //
-//   /tmp/kustomize-test-random
-//   ├── base
-//   │   ├── okayData
-//   │   ├── symLinkToOkayData -> okayData
-//   │   └── symLinkToExteriorData -> ../exteriorData
-//   └── exteriorData
-//
+//	/tmp/kustomize-test-random
+//	├── base
+//	│   ├── okayData
+//	│   ├── symLinkToOkayData -> okayData
+//	│   └── symLinkToExteriorData -> ../exteriorData
+//	└── exteriorData
 func commonSetupForLoaderRestrictionTest() (string, filesys.FileSystem, error) {
 	dir, err := ioutil.TempDir("", "kustomize-test-")
 	if err != nil {
@@ -340,6 +339,78 @@ func TestRestrictionNoneInRealLoader(t *testing.T) {
 	}
 }
 
+func TestLoaderGlob(t *testing.T) {
+	fSys := MakeFakeFs(append(testCases,
+		testData{path: "foo/outside.yaml", expectedContent: "outside"}))
+	l := newLoaderOrDie(RestrictionRootOnly, fSys, "/foo/project")
+	for pattern, expected := range map[string][]string{
+		"*.yaml":              {"fileA.yaml", "fileD.yaml"},
+		"subdir1":             {"subdir1/fileB.yaml"},
+		"subdir*/*.yaml":      {"subdir1/fileB.yaml", "subdir2/fileC.yaml"},
+		"fileD.yaml":          {"fileD.yaml"},
+		"/foo/project/*A.*":   {"/foo/project/fileA.yaml"},
+		"./subdir2/file?.ya*": {"subdir2/fileC.yaml"},
+	} {
+		actual, err := l.Glob(pattern)
+		if err != nil {
+			t.Fatalf("unexpected error globbing %s: %v", pattern, err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("globbing %s expected %v, but got %v", pattern, expected, actual)
+		}
+	}
+
+	_, err := l.Glob("*.json")
+	if err == nil || err.Error() != "no files match '*.json'" {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	_, err = l.Glob("../*.yaml")
+	if err == nil || !strings.Contains(err.Error(), "is not in or below") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	l = newLoaderOrDie(RestrictionNone, fSys, "/foo/project")
+	actual, err := l.Glob("../*.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{"../outside.yaml"}, actual) {
+		t.Fatalf("unexpected matches: %v", actual)
+	}
+}
+
+func TestLoaderTree(t *testing.T) {
+	fSys := MakeFakeFs(append(testCases,
+		testData{path: "foo/outside.yaml", expectedContent: "outside"}))
+	l := newLoaderOrDie(RestrictionRootOnly, fSys, "/foo/project")
+	for dir, expected := range map[string][]string{
+		".": {
+			"fileA.yaml", "fileD.yaml",
+			"subdir1/fileB.yaml", "subdir2/fileC.yaml"},
+		"subdir1":           {"fileB.yaml"},
+		"/foo/project/sub*": nil,
+	} {
+		actual, err := l.Tree(dir)
+		if expected == nil {
+			if err == nil || err.Error() != "'"+dir+"' must be a directory" {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error listing %s: %v", dir, err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("listing %s expected %v, but got %v", dir, expected, actual)
+		}
+	}
+
+	_, err := l.Tree("..")
+	if err == nil || !strings.Contains(err.Error(), "is not in or below") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
 func splitOnNthSlash(v string, n int) (string, string) {
 	left := ""
 	for i := 0; i < n; i++ {
@@ -9,6 +9,8 @@ import (
 	"reflect"
 
 	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/celexpr"
+	"sigs.k8s.io/kustomize/api/internal/fieldcond"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/kio"
@@ -509,9 +511,12 @@ func (m *resWrangler) appendReplaceOrMerge(res *resource.Resource) error {
 		case types.BehaviorReplace:
 			res.CopyMergeMetaDataFieldsFrom(old)
 		case types.BehaviorMerge:
+			// Merge the data first; it needs the generator
+			// options of res, which the metadata merge drops.
+			if err := res.MergeDataMapsEditingKeysFrom(old); err != nil {
+				return err
+			}
 			res.CopyMergeMetaDataFieldsFrom(old)
-			res.MergeDataMapFrom(old)
-			res.MergeBinaryDataMapFrom(old)
 		default:
 			return fmt.Errorf(
 				"id %#v exists; behavior must be merge or replace", id)
@@ -539,6 +544,17 @@ func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
 	if err != nil {
 		return nil, err
 	}
+	var expr *celexpr.Expression
+	if s.Expression != "" {
+		expr, err = celexpr.Compile(s.Expression)
+		if err != nil {
+			return nil, err
+		}
+	}
+	conditions, err := fieldcond.Parse(s.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
 	for _, r := range m.rList {
 		curId := r.CurId()
 		orgId := r.OrgId()
@@ -579,6 +595,16 @@ func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
 		if !matched {
 			continue
 		}
+
+		// matches the expression
+		if expr != nil && !expr.Matches(&r.RNode) {
+			continue
+		}
+
+		// matches the field conditions
+		if !fieldcond.MatchesAll(conditions, &r.RNode) {
+			continue
+		}
 		result = append(result, r)
 	}
 	return result, nil
@@ -179,6 +179,45 @@ func TestFindPatchTargets(t *testing.T) {
 			},
 			count: 1,
 		},
+		"select_19": {
+			target: types.Selector{
+				Expression: `has(object.metadata.annotations) && object.metadata.annotations.foo == "bar"`,
+			},
+			count: 2,
+		},
+		"select_20": {
+			target: types.Selector{
+				ResId:      resid.ResId{Gvk: resid.Gvk{Kind: "Kind2"}},
+				Expression: `object.metadata.name.startsWith("x-")`,
+			},
+			count: 1,
+		},
+		"select_21": {
+			// an error evaluating the expression doesn't match
+			target: types.Selector{
+				Expression: `object.metadata.labels.app != ""`,
+			},
+			count: 3,
+		},
+		"select_22": {
+			target: types.Selector{
+				FieldSelector: "metadata.annotations.foo=bar,metadata.namespace~ns.*",
+			},
+			count: 1,
+		},
+		"select_23": {
+			target: types.Selector{
+				ResId: resid.ResId{Gvk: resid.Gvk{Kind: "Kind2"}},
+				FieldSelector: "metadata.labels",
+			},
+			count: 1,
+		},
+		"select_24": {
+			target: types.Selector{
+				FieldSelector: "!metadata.namespace",
+			},
+			count: 1,
+		},
 	}
 	for n, testcase := range testcases {
 		actual, err := rm.Select(testcase.target)
@@ -187,3 +226,21 @@ func TestFindPatchTargets(t *testing.T) {
 			t, testcase.count, len(actual), "test=%s target=%v", n, testcase.target)
 	}
 }
+
+func TestFindPatchTargetsInvalidFieldSelector(t *testing.T) {
+	rm := setupRMForPatchTargets(t)
+	_, err := rm.Select(types.Selector{
+		FieldSelector: "=bar",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "empty path element")
+	}
+}
+
+func TestFindPatchTargetsInvalidExpression(t *testing.T) {
+	rm := setupRMForPatchTargets(t)
+	_, err := rm.Select(types.Selector{Expression: "object.kind +"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid expression "object.kind +"`)
+	}
+}
@@ -50,6 +50,9 @@ var buildAnnotations = []string{
 	buildAnnotationPreviousNamespaces,
 	buildAnnotationAllowNameChange,
 	buildAnnotationAllowKindChange,
+	types.ConflictPolicyAnnotation,
+	types.ContentHashAnnotation,
+	types.SecretOutputAnnotation,
 }
 
 func (r *Resource) ResetRNode(incoming *Resource) {
@@ -60,7 +63,20 @@ func (r *Resource) GetGvk() resid.Gvk {
 	return resid.GvkFromNode(&r.RNode)
 }
 
+// Hash returns the hash of the resource, configured
+// by the hash options of its generator, if any.
 func (r *Resource) Hash(h ifc.KustHasher) (string, error) {
+	if o := r.options.HashOptions(); o != (types.HashOptions{}) {
+		c, ok := h.(ifc.ConfigurableKustHasher)
+		if !ok {
+			return "", fmt.Errorf(
+				"hasher %T of %s cannot honor hash options", h, r.CurId())
+		}
+		var err error
+		if h, err = c.WithOptions(o); err != nil {
+			return "", fmt.Errorf("%s: %v", r.CurId(), err)
+		}
+	}
 	return h.Hash(&r.RNode)
 }
 
@@ -129,6 +145,71 @@ func (r *Resource) MergeBinaryDataMapFrom(o *Resource) {
 	r.SetBinaryDataMap(mergeStringMaps(o.GetBinaryDataMap(), r.GetBinaryDataMap()))
 }
 
+// MergeDataMapsEditingKeysFrom merges the data and binaryData of o
+// into those of r, whose values win, after dropping and renaming
+// the keys of o as the generator args of r tell.  It's an error to
+// drop or rename a key o doesn't have, or to rename one onto a key
+// o keeps; r is left unchanged then.
+func (r *Resource) MergeDataMapsEditingKeysFrom(o *Resource) error {
+	data, err := r.editMergedKeys(o.GetDataMap(), o.GetBinaryDataMap())
+	if err != nil {
+		return err
+	}
+	binaryData, err := r.editMergedKeys(o.GetBinaryDataMap(), o.GetDataMap())
+	if err != nil {
+		return err
+	}
+	r.SetDataMap(mergeStringMaps(data, r.GetDataMap()))
+	r.SetBinaryDataMap(mergeStringMaps(binaryData, r.GetBinaryDataMap()))
+	return nil
+}
+
+// editMergedKeys returns a copy of m, without the removed keys
+// and with the renamed keys moved.  A key to remove or rename
+// may be in either m or other, the other data map of the same
+// resource, but must be in one of them.
+func (r *Resource) editMergedKeys(
+	m, other map[string]string) (map[string]string, error) {
+	result := mergeStringMaps(m)
+	remaining := mergeStringMaps(m, other)
+	for _, k := range r.options.RemoveKeys() {
+		if _, ok := remaining[k]; !ok {
+			return nil, fmt.Errorf(
+				"cannot remove key '%s' of %s; it doesn't exist", k, r.CurId())
+		}
+		delete(remaining, k)
+		delete(result, k)
+	}
+	renames := r.options.RenameKeys()
+	for _, k := range kyaml.SortedMapKeys(renames) {
+		if _, ok := remaining[k]; !ok {
+			return nil, fmt.Errorf(
+				"cannot rename key '%s' of %s; it doesn't exist", k, r.CurId())
+		}
+	}
+	for _, k := range kyaml.SortedMapKeys(renames) {
+		newKey := renames[k]
+		if _, ok := remaining[newKey]; ok {
+			if _, moved := renames[newKey]; !moved {
+				return nil, fmt.Errorf(
+					"cannot rename key '%s' of %s to '%s'; it exists",
+					k, r.CurId(), newKey)
+			}
+		}
+	}
+	moved := make(map[string]string)
+	for k, newKey := range renames {
+		if v, ok := result[k]; ok {
+			moved[newKey] = v
+			delete(result, k)
+		}
+	}
+	for k, v := range moved {
+		result[k] = v
+	}
+	return result, nil
+}
+
 func (r *Resource) ErrIfNotEquals(o *Resource) error {
 	meYaml, err := r.AsYAML()
 	if err != nil {
@@ -257,7 +338,9 @@ func (r *Resource) PrefixesSuffixesEquals(o ResCtx) bool {
 
 // RemoveBuildAnnotations removes annotations created by the build process.
 // These are internal-only to kustomize, added to the data pipeline to
-// track name changes so name references can be fixed.
+// track name changes so name references can be fixed, along with
+// the conflict policy and content hash annotations, which only
+// direct the build.
 func (r *Resource) RemoveBuildAnnotations() {
 	annotations := r.GetAnnotations()
 	if len(annotations) == 0 {
@@ -346,6 +429,11 @@ func (r *Resource) Behavior() types.GenerationBehavior {
 	return r.options.Behavior()
 }
 
+// IsGenerated returns true if a generator made the resource.
+func (r *Resource) IsGenerated() bool {
+	return r.options.IsGenerated()
+}
+
 // NeedHashSuffix returns true if a resource content
 // hash should be appended to the name of the resource.
 func (r *Resource) NeedHashSuffix() bool {
@@ -319,6 +319,107 @@ metadata:
 `, string(bytes))
 }
 
+func TestMergeDataMapsEditingKeysFrom(t *testing.T) {
+	old := factory.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "cm"},
+		"data": map[string]interface{}{
+			"a": "1",
+			"b": "2",
+			"c": "3",
+		},
+		"binaryData": map[string]interface{}{
+			"bin": "AA==",
+		},
+	})
+	testCases := map[string]struct {
+		args       types.GeneratorArgs
+		data       map[string]string
+		binaryData map[string]string
+		err        string
+	}{
+		"remove": {
+			args: types.GeneratorArgs{RemoveKeys: []string{"a", "bin"}},
+			data: map[string]string{"b": "2", "c": "3", "new": "x"},
+		},
+		"rename": {
+			args: types.GeneratorArgs{RenameKeys: map[string]string{
+				"a": "x", "bin": "y"}},
+			data:       map[string]string{"x": "1", "b": "2", "c": "3", "new": "x"},
+			binaryData: map[string]string{"y": "AA=="},
+		},
+		"swap": {
+			args: types.GeneratorArgs{RenameKeys: map[string]string{
+				"a": "b", "b": "a"}},
+			data:       map[string]string{"a": "2", "b": "1", "c": "3", "new": "x"},
+			binaryData: map[string]string{"bin": "AA=="},
+		},
+		"rename to removed key": {
+			args: types.GeneratorArgs{
+				RemoveKeys: []string{"b"},
+				RenameKeys: map[string]string{"a": "b"}},
+			data:       map[string]string{"b": "1", "c": "3", "new": "x"},
+			binaryData: map[string]string{"bin": "AA=="},
+		},
+		"new data wins over renamed key": {
+			args: types.GeneratorArgs{RenameKeys: map[string]string{
+				"a": "new"}},
+			data:       map[string]string{"b": "2", "c": "3", "new": "x"},
+			binaryData: map[string]string{"bin": "AA=="},
+		},
+		"remove missing key": {
+			args: types.GeneratorArgs{RemoveKeys: []string{"z"}},
+			err:  "cannot remove key 'z' of ~G_v1_ConfigMap|~X|cm; it doesn't exist",
+		},
+		"remove twice": {
+			args: types.GeneratorArgs{RemoveKeys: []string{"a", "a"}},
+			err:  "cannot remove key 'a' of ~G_v1_ConfigMap|~X|cm; it doesn't exist",
+		},
+		"rename missing key": {
+			args: types.GeneratorArgs{RenameKeys: map[string]string{"z": "y"}},
+			err:  "cannot rename key 'z' of ~G_v1_ConfigMap|~X|cm; it doesn't exist",
+		},
+		"rename removed key": {
+			args: types.GeneratorArgs{
+				RemoveKeys: []string{"a"},
+				RenameKeys: map[string]string{"a": "y"}},
+			err: "cannot rename key 'a' of ~G_v1_ConfigMap|~X|cm; it doesn't exist",
+		},
+		"rename to existing key": {
+			args: types.GeneratorArgs{RenameKeys: map[string]string{"a": "bin"}},
+			err:  "cannot rename key 'a' of ~G_v1_ConfigMap|~X|cm to 'bin'; it exists",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			args := tc.args
+			args.Behavior = "merge"
+			r := factory.FromMapAndOption(map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "cm"},
+				"data":       map[string]interface{}{"new": "x"},
+			}, &args)
+			err := r.MergeDataMapsEditingKeysFrom(old)
+			if tc.err != "" {
+				if !assert.EqualError(t, err, tc.err) {
+					t.FailNow()
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.data, r.GetDataMap())
+			if tc.binaryData == nil {
+				tc.binaryData = map[string]string{}
+			}
+			assert.Equal(t, tc.binaryData, r.GetBinaryDataMap())
+		})
+	}
+}
+
 func TestApplySmPatch_SwapOrder(t *testing.T) {
 	s1 := `
 apiVersion: example.com/v1
@@ -0,0 +1,30 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// CommandSource is a local command whose standard output
+// becomes the value of a key value pair, e.g.
+//
+//	key: version
+//	command: [git, describe, --tags]
+//	trimSpace: true
+//
+// The command runs in the kustomization root, only if the
+// build enables external sources.
+type CommandSource struct {
+	// Key of the pair.
+	Key string `json:"key" yaml:"key"`
+
+	// Command is the executable and its arguments.  It's run
+	// directly, not by a shell.
+	Command []string `json:"command" yaml:"command"`
+
+	// Timeout of the command, e.g. 10s.  Defaults to
+	// DefaultCommandSourceTimeout.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// TrimSpace removes leading and trailing white space,
+	// e.g. the newline ending the output, from the value.
+	TrimSpace bool `json:"trimSpace,omitempty" yaml:"trimSpace,omitempty"`
+}
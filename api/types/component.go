@@ -0,0 +1,136 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Types of component parameters.
+const (
+	ParameterTypeString  = "string"
+	ParameterTypeInteger = "integer"
+	ParameterTypeBoolean = "boolean"
+)
+
+// ComponentRef is an entry of the parameterizedComponents field,
+// referring to a component and supplying values for its parameters.
+type ComponentRef struct {
+	// Path is the relative path, absolute path or URL of the component.
+	Path string `json:"path" yaml:"path"`
+
+	// Parameters are the values of the component's parameters,
+	// by parameter name.
+	Parameters map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// ComponentParameter declares a parameter of a component.
+// References to it, written ${params.<name>}, in the files of the
+// component, including its kustomization file, are replaced
+// with its value before the component is accumulated.  In YAML
+// files the value only replaces text within scalars, so it can't
+// add fields; a scalar that is just a reference to an integer or
+// boolean parameter takes that type.
+type ComponentParameter struct {
+	// Name of the parameter.
+	Name string `json:"name" yaml:"name"`
+
+	// Type of the parameter, one of string, integer or boolean.
+	// Defaults to string.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Default is the value of the parameter if none is supplied.
+	// Parameters without a default are required.
+	Default interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Description of the parameter.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// ParameterReference returns the string replaced
+// by the value of the named parameter.
+func ParameterReference(name string) string {
+	return "${params." + name + "}"
+}
+
+// format returns the value as a string, or an
+// error if it isn't of the parameter's type.
+func (p *ComponentParameter) format(value interface{}) (string, error) {
+	switch p.Type {
+	case "", ParameterTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case ParameterTypeInteger:
+		switch n := value.(type) {
+		case float64:
+			if n == float64(int64(n)) {
+				return strconv.FormatInt(int64(n), 10), nil
+			}
+		case int:
+			return strconv.Itoa(n), nil
+		case int64:
+			return strconv.FormatInt(n, 10), nil
+		}
+	case ParameterTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return strconv.FormatBool(b), nil
+		}
+	default:
+		return "", fmt.Errorf(
+			"parameter '%s' has unknown type '%s'; must be one of %s, %s or %s",
+			p.Name, p.Type, ParameterTypeString,
+			ParameterTypeInteger, ParameterTypeBoolean)
+	}
+	t := p.Type
+	if t == "" {
+		t = ParameterTypeString
+	}
+	return "", fmt.Errorf(
+		"value %v of parameter '%s' isn't of type %s", value, p.Name, t)
+}
+
+// ResolveParameters returns the values of the declared parameters,
+// formatted for substitution, given the supplied values.  It's an
+// error to supply unknown parameters or to omit required ones.
+func ResolveParameters(
+	declared []ComponentParameter, supplied map[string]interface{}) (map[string]string, error) {
+	result := make(map[string]string, len(declared))
+	known := make(map[string]bool, len(declared))
+	for i := range declared {
+		p := &declared[i]
+		if p.Name == "" {
+			return nil, fmt.Errorf("parameter #%d must have a name", i)
+		}
+		if known[p.Name] {
+			return nil, fmt.Errorf("parameter '%s' is declared twice", p.Name)
+		}
+		known[p.Name] = true
+		value, found := supplied[p.Name]
+		if !found {
+			value = p.Default
+		}
+		if value == nil {
+			return nil, fmt.Errorf("missing value for parameter '%s'", p.Name)
+		}
+		s, err := p.format(value)
+		if err != nil {
+			return nil, err
+		}
+		result[p.Name] = s
+	}
+	var unknown []string
+	for name := range supplied {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown parameter(s) %v", unknown)
+	}
+	return result, nil
+}
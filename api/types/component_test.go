@@ -0,0 +1,127 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	. "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+func TestParameterizedComponentsUnmarshal(t *testing.T) {
+	var k Kustomization
+	err := yaml.Unmarshal([]byte(`
+components:
+- ../plain
+parameterizedComponents:
+- path: ../parameterized
+  parameters:
+    env: prod
+    replicas: 3
+`), &k)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(k.Components, []string{"../plain"}) {
+		t.Fatalf("unexpected components %v", k.Components)
+	}
+	expected := []ComponentRef{
+		{Path: "../parameterized", Parameters: map[string]interface{}{
+			"env": "prod", "replicas": float64(3)}},
+	}
+	if !reflect.DeepEqual(k.ParameterizedComponents, expected) {
+		t.Fatalf("expected %v, got %v", expected, k.ParameterizedComponents)
+	}
+}
+
+func TestResolveParameters(t *testing.T) {
+	declared := []ComponentParameter{
+		{Name: "env"},
+		{Name: "replicas", Type: ParameterTypeInteger, Default: 1},
+		{Name: "debug", Type: ParameterTypeBoolean, Default: false},
+	}
+	testCases := map[string]struct {
+		supplied map[string]interface{}
+		expected map[string]string
+		err      string
+	}{
+		"defaults": {
+			supplied: map[string]interface{}{"env": "prod"},
+			expected: map[string]string{
+				"env": "prod", "replicas": "1", "debug": "false"},
+		},
+		"supplied": {
+			supplied: map[string]interface{}{
+				"env": "dev", "replicas": float64(3), "debug": true},
+			expected: map[string]string{
+				"env": "dev", "replicas": "3", "debug": "true"},
+		},
+		"missing": {
+			supplied: nil,
+			err:      "missing value for parameter 'env'",
+		},
+		"unknown": {
+			supplied: map[string]interface{}{"env": "prod", "b": 1, "a": 2},
+			err:      "unknown parameter(s) [a b]",
+		},
+		"not an integer": {
+			supplied: map[string]interface{}{"env": "prod", "replicas": 1.5},
+			err:      "value 1.5 of parameter 'replicas' isn't of type integer",
+		},
+		"not a string": {
+			supplied: map[string]interface{}{"env": true},
+			err:      "value true of parameter 'env' isn't of type string",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ResolveParameters(declared, tc.supplied)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResolveParametersInvalidDeclarations(t *testing.T) {
+	testCases := map[string]struct {
+		declared []ComponentParameter
+		err      string
+	}{
+		"unnamed": {
+			declared: []ComponentParameter{{Default: "a"}},
+			err:      "parameter #0 must have a name",
+		},
+		"duplicate": {
+			declared: []ComponentParameter{
+				{Name: "a", Default: "a"}, {Name: "a", Default: "b"}},
+			err: "parameter 'a' is declared twice",
+		},
+		"unknown type": {
+			declared: []ComponentParameter{
+				{Name: "a", Type: "float", Default: 1.5}},
+			err: "parameter 'a' has unknown type 'float'",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ResolveParameters(tc.declared, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
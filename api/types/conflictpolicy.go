@@ -0,0 +1,48 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "fmt"
+
+// ConflictPolicy says what to do with a resource
+// having the id of an already accumulated resource.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyError fails the build; the default.
+	ConflictPolicyError ConflictPolicy = "error"
+	// ConflictPolicyKeepFirst drops the later copy.
+	ConflictPolicyKeepFirst ConflictPolicy = "keep-first"
+	// ConflictPolicyKeepLast replaces the earlier copy with the later one.
+	ConflictPolicyKeepLast ConflictPolicy = "keep-last"
+	// ConflictPolicyMergeIfEqual keeps one copy if the copies
+	// are equal, and fails the build otherwise.
+	ConflictPolicyMergeIfEqual ConflictPolicy = "merge-if-equal"
+)
+
+// ConflictPolicyAnnotation sets the conflict policy of a resource,
+// overriding that of the kustomizations accumulating it.
+const ConflictPolicyAnnotation = "kustomize.config.k8s.io/conflict-policy"
+
+var conflictPolicies = []ConflictPolicy{
+	ConflictPolicyError,
+	ConflictPolicyKeepFirst,
+	ConflictPolicyKeepLast,
+	ConflictPolicyMergeIfEqual,
+}
+
+// Validate returns an error unless the policy is
+// empty, meaning the default, or a known one.
+func (p ConflictPolicy) Validate() error {
+	if p == "" {
+		return nil
+	}
+	for _, known := range conflictPolicies {
+		if p == known {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"unknown conflict policy '%s'; must be one of %v", p, conflictPolicies)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "fmt"
+
+// ContentHashMode says how a resource's content hash is used.
+type ContentHashMode string
+
+const (
+	// ContentHashSuffix appends the hash to the resource's
+	// name, as done for generated ConfigMaps and Secrets;
+	// the default.
+	ContentHashSuffix ContentHashMode = "suffix"
+	// ContentHashChecksum keeps the resource's name, and stamps
+	// a checksum/<name> annotation holding the hash on the pod
+	// templates of the workloads referring to it, so that
+	// their pods roll when the resource changes.
+	ContentHashChecksum ContentHashMode = "checksum"
+)
+
+// ContentHashAnnotation opts a resource into content
+// hashing; its value is the ContentHashMode.
+const ContentHashAnnotation = "kustomize.config.k8s.io/content-hash"
+
+var contentHashModes = []ContentHashMode{
+	ContentHashSuffix,
+	ContentHashChecksum,
+}
+
+// Validate returns an error unless the mode is
+// empty, meaning the default, or a known one.
+func (m ContentHashMode) Validate() error {
+	if m == "" {
+		return nil
+	}
+	for _, known := range contentHashModes {
+		if m == known {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"unknown content hash mode '%s'; must be one of %v", m, contentHashModes)
+}
+
+// ContentHash opts the resources selected by
+// the targets into content hashing.
+type ContentHash struct {
+	// Mode of the hashing; defaults to suffix.
+	Mode ContentHashMode `json:"mode,omitempty" yaml:"mode,omitempty"`
+
+	// Targets select the resources to hash.
+	Targets []Selector `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// DefaultDirectoryKeySeparator replaces the slashes of
+// the paths of the files of a DirectorySource in their keys.
+const DefaultDirectoryKeySeparator = "_"
+
+// VolumeItemsAnnotation holds, on a ConfigMap or Secret generated
+// from directory sources, the JSON list of the KeyToPath items of a
+// volume restoring their trees, e.g.
+//
+//	[{"key":"conf.d_default.conf","path":"conf.d/default.conf"}]
+const VolumeItemsAnnotation = "kustomize.config.k8s.io/volume-items"
+
+// DirectorySource is a directory whose files, in the whole
+// tree under it, become key value pairs.  A file is keyed
+// by its path relative to the directory, the slashes replaced
+// by KeySeparator, e.g. conf.d/default.conf becomes
+// conf.d_default.conf.
+//
+// The patterns of Include and Exclude are matched, as by
+// path.Match, against the slash separated relative path
+// of a file or, if they have no slash, its base name.
+type DirectorySource struct {
+	// Path to the directory.
+	Path string `json:"path" yaml:"path"`
+
+	// KeySeparator defaults to DefaultDirectoryKeySeparator.
+	KeySeparator string `json:"keySeparator,omitempty" yaml:"keySeparator,omitempty"`
+
+	// Include, if not empty, selects the files matching any
+	// of the patterns.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+
+	// Exclude drops the files matching any of the patterns.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// KeyToPath is an item of a ConfigMap or Secret volume,
+// projecting the value of a key to a path in the volume.
+type KeyToPath struct {
+	Key  string `json:"key" yaml:"key"`
+	Path string `json:"path" yaml:"path"`
+}
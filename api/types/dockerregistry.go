@@ -0,0 +1,24 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// DockerRegistry holds the credentials for an image registry,
+// from which a Secret of type "kubernetes.io/dockerconfigjson"
+// builds its ".dockerconfigjson" key.
+type DockerRegistry struct {
+	// Registry is the address of the registry,
+	// e.g. https://index.docker.io/v1/ or ghcr.io.
+	Registry string `json:"registry,omitempty" yaml:"registry,omitempty"`
+
+	// Username to log into the registry with.
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+
+	// PasswordFile is the path to a file holding the password,
+	// or token, to log into the registry with.  Trailing
+	// newlines are dropped.
+	PasswordFile string `json:"passwordFile,omitempty" yaml:"passwordFile,omitempty"`
+
+	// Email of the user, optional.
+	Email string `json:"email,omitempty" yaml:"email,omitempty"`
+}
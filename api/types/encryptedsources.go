@@ -0,0 +1,41 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// EncryptedSources are files encrypted with SOPS, using age keys,
+// or with age itself.  They're decrypted in memory when their
+// Secret is generated; the plaintext is never written to disk.
+//
+// SOPS files are YAML, JSON or dotenv files, as told by their
+// extension; files with any other extension hold SOPS encrypted
+// binary data.
+type EncryptedSources struct {
+	// FileSources are like KvPairSources.FileSources,
+	// but the value is the decrypted file content.
+	FileSources []string `json:"files,omitempty" yaml:"files,omitempty"`
+
+	// EnvSources are like KvPairSources.EnvSources, but each
+	// file is a SOPS encrypted dotenv file, or an age encrypted
+	// env file, which is decrypted before its lines are read.
+	EnvSources []string `json:"envs,omitempty" yaml:"envs,omitempty"`
+
+	// AgeKeys says where to find the age identities used to
+	// decrypt the sources.  If unset, they're read as SOPS
+	// reads them: from the SOPS_AGE_KEY environment variable,
+	// the file named by SOPS_AGE_KEY_FILE, or else the
+	// sops/age/keys.txt file in the user's config directory.
+	AgeKeys *AgeKeys `json:"ageKeys,omitempty" yaml:"ageKeys,omitempty"`
+}
+
+// AgeKeys are sources of age identities.
+type AgeKeys struct {
+	// File is the path to an age identity file, as written by
+	// age-keygen.  It's read like the sources, so it's subject
+	// to the load restrictions.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// Env is the name of an environment
+	// variable holding age identities.
+	Env string `json:"env,omitempty" yaml:"env,omitempty"`
+}
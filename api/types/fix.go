@@ -4,8 +4,10 @@
 package types
 
 import (
+	"fmt"
 	"regexp"
 
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/yaml"
 )
 
@@ -28,7 +30,56 @@ func FixKustomizationPreUnmarshalling(data []byte) ([]byte, error) {
 		pattern := regexp.MustCompile("patches:")
 		data = pattern.ReplaceAll(data, []byte("patchesStrategicMerge:"))
 	}
-	return data, nil
+	return movePatchesWithOptions(data)
+}
+
+// movePatchesWithOptions moves the entries of patchesStrategicMerge
+// written as objects, holding a path and options as the entries of
+// patches do, to the front of patches, where the options apply.
+// The entries written as strings stay where they are.
+func movePatchesWithOptions(data []byte) ([]byte, error) {
+	node, err := kyaml.Parse(string(data))
+	if err != nil {
+		// left to the unmarshalling to report
+		return data, nil
+	}
+	psm := node.Field("patchesStrategicMerge")
+	if psm == nil || psm.Value.YNode().Kind != kyaml.SequenceNode {
+		return data, nil
+	}
+	var paths, withOptions []*kyaml.Node
+	for _, n := range psm.Value.Content() {
+		if n.Kind != kyaml.MappingNode {
+			paths = append(paths, n)
+			continue
+		}
+		for i := 0; i < len(n.Content); i += 2 {
+			if k := n.Content[i].Value; k != "path" && k != "options" {
+				return nil, fmt.Errorf(
+					"an entry of patchesStrategicMerge may only "+
+						"hold a path and options, not %s", k)
+			}
+		}
+		withOptions = append(withOptions, n)
+	}
+	if len(withOptions) == 0 {
+		return data, nil
+	}
+	if len(paths) == 0 {
+		_, err = node.Pipe(kyaml.Clear("patchesStrategicMerge"))
+	} else {
+		psm.Value.YNode().Content = paths
+	}
+	if err != nil {
+		return nil, err
+	}
+	patches, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "patches"))
+	if err != nil {
+		return nil, err
+	}
+	patches.YNode().Content = append(withOptions, patches.Content()...)
+	s, err := node.String()
+	return []byte(s), err
 }
 
 func useLegacyPatch(data []byte) (bool, error) {
@@ -0,0 +1,78 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/types"
+)
+
+func TestFixKustomizationPreUnmarshallingPatchesWithOptions(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"strings only": {
+			input: `
+patchesStrategicMerge:
+- a.yaml
+`,
+			expected: `
+patchesStrategicMerge:
+- a.yaml
+`,
+		},
+		"objects moved to patches": {
+			input: `# keep me
+patchesStrategicMerge:
+- a.yaml
+- path: b.yaml
+  options:
+    optional: true
+patches:
+- path: c.yaml
+`,
+			expected: `# keep me
+patchesStrategicMerge:
+- a.yaml
+patches:
+- path: b.yaml
+  options:
+    optional: true
+- path: c.yaml
+`,
+		},
+		"objects only": {
+			input: `
+patchesStrategicMerge:
+- path: b.yaml
+  options:
+    failIfNoMatch: true
+`,
+			expected: `patches:
+- path: b.yaml
+  options:
+    failIfNoMatch: true
+`,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			actual, err := FixKustomizationPreUnmarshalling([]byte(tc.input))
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, string(actual))
+			}
+		})
+	}
+
+	_, err := FixKustomizationPreUnmarshalling([]byte(`
+patchesStrategicMerge:
+- path: b.yaml
+  patch: "{}"
+`))
+	assert.EqualError(t, err,
+		"an entry of patchesStrategicMerge may only hold a path and options, not patch")
+}
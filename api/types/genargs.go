@@ -37,6 +37,22 @@ func (g *GenArgs) ShouldAddHashSuffixToName() bool {
 		(g.args.Options == nil || !g.args.Options.DisableNameSuffixHash)
 }
 
+// HashOptions returns the options of the name suffix hash.
+func (g *GenArgs) HashOptions() HashOptions {
+	if g == nil || g.args == nil || g.args.Options == nil {
+		return HashOptions{}
+	}
+	return HashOptions{
+		Length: g.args.Options.HashLength,
+		Salt:   g.args.Options.HashSalt,
+	}
+}
+
+// IsGenerated tells whether the resource was made by a generator.
+func (g *GenArgs) IsGenerated() bool {
+	return g != nil && g.args != nil
+}
+
 // Behavior returns Behavior field of GeneratorArgs
 func (g *GenArgs) Behavior() GenerationBehavior {
 	if g == nil || g.args == nil {
@@ -44,3 +60,19 @@ func (g *GenArgs) Behavior() GenerationBehavior {
 	}
 	return NewGenerationBehavior(g.args.Behavior)
 }
+
+// RemoveKeys returns RemoveKeys field of GeneratorArgs
+func (g *GenArgs) RemoveKeys() []string {
+	if g == nil || g.args == nil {
+		return nil
+	}
+	return g.args.RemoveKeys
+}
+
+// RenameKeys returns RenameKeys field of GeneratorArgs
+func (g *GenArgs) RenameKeys() map[string]string {
+	if g == nil || g.args == nil {
+		return nil
+	}
+	return g.args.RenameKeys
+}
@@ -19,6 +19,17 @@ type GeneratorArgs struct {
 	//   'merge': merge with the existing one
 	Behavior string `json:"behavior,omitempty" yaml:"behavior,omitempty"`
 
+	// RemoveKeys are keys of the data of the existing resource
+	// to drop when merging with it.  Each must exist.
+	// Requires the 'merge' behavior.
+	RemoveKeys []string `json:"removeKeys,omitempty" yaml:"removeKeys,omitempty"`
+
+	// RenameKeys maps keys of the data of the existing resource
+	// to the keys to move their values to when merging with it,
+	// after RemoveKeys are dropped.  Each must exist, and the new
+	// keys must not.  Requires the 'merge' behavior.
+	RenameKeys map[string]string `json:"renameKeys,omitempty" yaml:"renameKeys,omitempty"`
+
 	// KvPairSources for the generator.
 	KvPairSources `json:",inline,omitempty" yaml:",inline,omitempty"`
 
@@ -3,6 +3,8 @@
 
 package types
 
+import "fmt"
+
 // GeneratorOptions modify behavior of all ConfigMap and Secret generators.
 type GeneratorOptions struct {
 	// Labels to add to all generated resources.
@@ -18,6 +20,54 @@ type GeneratorOptions struct {
 
 	// Immutable if true add to all generated resources.
 	Immutable bool `json:"immutable,omitempty" yaml:"immutable,omitempty"`
+
+	// HashLength is the length of the name suffix hash,
+	// between MinHashLength and MaxHashLength.
+	// Defaults to DefaultHashLength.
+	HashLength int `json:"hashLength,omitempty" yaml:"hashLength,omitempty"`
+
+	// HashSalt, if set, keys the name suffix hash, so that
+	// the same contents hash differently under other salts.
+	HashSalt string `json:"hashSalt,omitempty" yaml:"hashSalt,omitempty"`
+}
+
+const (
+	// DefaultHashLength is the length of name suffix hashes.
+	DefaultHashLength = 10
+	// MinHashLength is the shortest name suffix hash allowed.
+	MinHashLength = 5
+	// MaxHashLength is the longest name suffix hash allowed,
+	// the length of the hex form of a sha256 sum.
+	MaxHashLength = 64
+)
+
+// HashOptions configure the hash suffixing the names
+// of generated resources.
+type HashOptions struct {
+	// Length of the hash; zero means DefaultHashLength.
+	Length int
+
+	// Salt keying the hash; empty means none.
+	Salt string
+}
+
+// Validate returns an error if the length is out of bounds.
+func (o HashOptions) Validate() error {
+	if o.Length != 0 &&
+		(o.Length < MinHashLength || o.Length > MaxHashLength) {
+		return fmt.Errorf("hashLength %d must be between %d and %d",
+			o.Length, MinHashLength, MaxHashLength)
+	}
+	return nil
+}
+
+// Validate returns an error if the options are invalid, whether
+// or not the generated resources get a name suffix hash.
+func (o *GeneratorOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	return HashOptions{Length: o.HashLength, Salt: o.HashSalt}.Validate()
 }
 
 // MergeGlobalOptionsIntoLocal merges two instances of GeneratorOptions.
@@ -48,6 +98,12 @@ func MergeGlobalOptionsIntoLocal(
 	if globalOpts.Immutable {
 		localOpts.Immutable = true
 	}
+	if localOpts.HashLength == 0 {
+		localOpts.HashLength = globalOpts.HashLength
+	}
+	if localOpts.HashSalt == "" {
+		localOpts.HashSalt = globalOpts.HashSalt
+	}
 	return localOpts
 }
 
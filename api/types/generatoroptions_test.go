@@ -126,6 +126,20 @@ func TestMergeGlobalOptionsIntoLocal(t *testing.T) {
 				Immutable:             true,
 			},
 		},
+		{
+			name: "global hash options fill in local ones",
+			local: &GeneratorOptions{
+				HashLength: 6,
+			},
+			global: &GeneratorOptions{
+				HashLength: 8,
+				HashSalt:   "pepper",
+			},
+			expected: &GeneratorOptions{
+				HashLength: 6,
+				HashSalt:   "pepper",
+			},
+		},
 	}
 	for _, tc := range tests {
 		actual := MergeGlobalOptionsIntoLocal(tc.local, tc.global)
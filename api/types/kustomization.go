@@ -44,6 +44,10 @@ type Kustomization struct {
 	// Namespace to add to all objects.
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
+	// NamespaceOptions say what to do about the Namespace object
+	// of the namespace, e.g. generate it.
+	NamespaceOptions *NamespaceOptions `json:"namespaceOptions,omitempty" yaml:"namespaceOptions,omitempty"`
+
 	// CommonLabels to add to all objects and selectors.
 	CommonLabels map[string]string `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty"`
 
@@ -57,6 +61,9 @@ type Kustomization struct {
 	// containing a strategic merge patch.  Format documented at
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/strategic-merge-patch.md
 	// URLs and globs are not supported.
+	// In a kustomization file, an entry may also be an object holding
+	// the path and options, e.g. optional, which is moved to Patches
+	// when the file is read.
 	PatchesStrategicMerge []PatchStrategicMerge `json:"patchesStrategicMerge,omitempty" yaml:"patchesStrategicMerge,omitempty"`
 
 	// JSONPatches is a list of JSONPatch for applying JSON patch.
@@ -104,6 +111,14 @@ type Kustomization struct {
 	// via relative paths, absolute paths, or URLs.
 	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
 
+	// ParameterizedComponents specifies Components, like Components,
+	// together with values for their parameters.  They're accumulated
+	// after those in Components.
+	ParameterizedComponents []ComponentRef `json:"parameterizedComponents,omitempty" yaml:"parameterizedComponents,omitempty"`
+
+	// Parameters declares the parameters of a Component.
+	Parameters []ComponentParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+
 	// Crds specifies relative paths to Custom Resource Definition files.
 	// This allows custom resources to be recognized as operands, making
 	// it possible to add them to the Resources list.
@@ -115,6 +130,22 @@ type Kustomization struct {
 	// be specified in the Resources field instead.
 	Bases []string `json:"bases,omitempty" yaml:"bases,omitempty"`
 
+	// SortOptions configure the order of the resources put out by
+	// building this kustomization when asked for a custom order, e.g.
+	// with --reorder=custom.  Ignored in kustomizations used as bases.
+	SortOptions *SortOptions `json:"sortOptions,omitempty" yaml:"sortOptions,omitempty"`
+
+	// ConflictPolicy says what to do when a resource has the id of one
+	// accumulated before, e.g. when two bases hold the same Namespace.
+	// One of error, keep-first, keep-last or merge-if-equal; defaults to
+	// error.  Resources may override it with the conflict policy annotation.
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty" yaml:"conflictPolicy,omitempty"`
+
+	// ContentHashes opt resources, of any kind, into content hashing,
+	// like that of generated ConfigMaps and Secrets.  Resources may
+	// also opt in with the content hash annotation.
+	ContentHashes []ContentHash `json:"contentHashes,omitempty" yaml:"contentHashes,omitempty"`
+
 	//
 	// Generators (operators that create operands)
 	//
@@ -244,6 +275,33 @@ func (k *Kustomization) EnforceFields() []string {
 	if k.APIVersion != "" && k.APIVersion != requiredVersion {
 		errs = append(errs, "apiVersion for "+k.Kind+" should be "+requiredVersion)
 	}
+	if len(k.Parameters) > 0 && k.Kind != ComponentKind {
+		errs = append(errs, "only a "+ComponentKind+" can declare parameters")
+	}
+	if err := k.ConflictPolicy.Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, ch := range k.ContentHashes {
+		if err := ch.Mode.Validate(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if err := k.GeneratorOptions.Validate(); err != nil {
+		errs = append(errs, "generatorOptions: "+err.Error())
+	}
+	for _, g := range k.ConfigMapGenerator {
+		if err := g.Options.Validate(); err != nil {
+			errs = append(errs, "configMapGenerator "+g.Name+": "+err.Error())
+		}
+	}
+	for _, g := range k.SecretGenerator {
+		if err := g.Options.Validate(); err != nil {
+			errs = append(errs, "secretGenerator "+g.Name+": "+err.Error())
+		}
+	}
+	if k.NamespaceOptions != nil && k.NamespaceOptions.Create && k.Namespace == "" {
+		errs = append(errs, "namespaceOptions.create requires a namespace")
+	}
 	return errs
 }
 
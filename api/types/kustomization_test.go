@@ -223,3 +223,29 @@ unknown`)
 		t.Fatalf("expect an error")
 	}
 }
+
+func TestEnforceFields_HashLength(t *testing.T) {
+	k := Kustomization{
+		GeneratorOptions: &GeneratorOptions{
+			DisableNameSuffixHash: true,
+			HashLength:            3,
+		},
+		ConfigMapGenerator: []ConfigMapArgs{{GeneratorArgs: GeneratorArgs{
+			Name:    "cm",
+			Options: &GeneratorOptions{HashLength: 65},
+		}}},
+		SecretGenerator: []SecretArgs{{GeneratorArgs: GeneratorArgs{
+			Name:    "secret",
+			Options: &GeneratorOptions{HashLength: 8},
+		}}},
+	}
+
+	errs := k.EnforceFields()
+	expected := []string{
+		"generatorOptions: hashLength 3 must be between 5 and 64",
+		"configMapGenerator cm: hashLength 65 must be between 5 and 64",
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Fatalf("errors should be %v but got: %v", expected, errs)
+	}
+}
@@ -3,6 +3,9 @@
 
 package types
 
+// EnvFormatDotenv is the EnvFormat of .env files.
+const EnvFormatDotenv = "dotenv"
+
 // KvPairSources defines places to obtain key value pairs.
 type KvPairSources struct {
 	// LiteralSources is a list of literal
@@ -29,6 +32,38 @@ type KvPairSources struct {
 	// (wikipedia.org/wiki/INI_file)
 	EnvSources []string `json:"envs,omitempty" yaml:"envs,omitempty"`
 
+	// EnvFormat is the syntax of the EnvSources: empty for
+	// plain key=value lines, or "dotenv" for the quotes,
+	// escapes, export prefixes, inline comments and multi-line
+	// values of the .env files used by e.g. docker-compose.
+	EnvFormat string `json:"envFormat,omitempty" yaml:"envFormat,omitempty"`
+
+	// ExpandEnvVars replaces ${VAR}, $VAR, ${VAR:-default} and
+	// ${VAR-default} in unquoted and double quoted dotenv values
+	// with the values of the keys before them.  Requires the
+	// "dotenv" EnvFormat.
+	ExpandEnvVars bool `json:"expandEnvVars,omitempty" yaml:"expandEnvVars,omitempty"`
+
+	// StructuredSources are JSON, YAML, TOML or Java properties
+	// files whose leaf values, or selected subtrees, become pairs.
+	// See StructuredSource.
+	StructuredSources []StructuredSource `json:"structured,omitempty" yaml:"structured,omitempty"`
+
+	// DirectorySources are directory trees whose files
+	// become pairs.  See DirectorySource.
+	DirectorySources []DirectorySource `json:"directories,omitempty" yaml:"directories,omitempty"`
+
+	// EnvVarSources are names of environment variables of the
+	// build, whose values become pairs.  The key defaults to the
+	// name, or may be given as in FileSources, e.g. key=NAME.
+	// Requires the build to enable external sources.
+	EnvVarSources []string `json:"envVars,omitempty" yaml:"envVars,omitempty"`
+
+	// CommandSources are local commands whose output become
+	// pairs.  Requires the build to enable external sources.
+	// See CommandSource.
+	CommandSources []CommandSource `json:"commands,omitempty" yaml:"commands,omitempty"`
+
 	// Older, singular form of EnvSources.
 	// On edits (e.g. `kustomize fix`) this is merged into the plural form
 	// for consistency with LiteralSources and FileSources.
@@ -0,0 +1,18 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// NamespaceOptions say what to do about the Namespace
+// object of the kustomization's namespace.
+type NamespaceOptions struct {
+	// Create if true generates the Namespace object, unless
+	// the resources hold it already.  Requires the namespace.
+	Create bool `json:"create,omitempty" yaml:"create,omitempty"`
+
+	// Labels to add to the Namespace object.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Annotations to add to the Namespace object.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
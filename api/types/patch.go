@@ -5,12 +5,38 @@ package types
 
 import "reflect"
 
+const (
+	// PatchOptionFailIfNoMatch makes it an error for a patch
+	// to match no resources.
+	PatchOptionFailIfNoMatch = "failIfNoMatch"
+
+	// PatchOptionOptional lets a patch match no resources, even
+	// if PatchConfig.FailIfNoMatch is set for the whole build.
+	PatchOptionOptional = "optional"
+)
+
+const (
+	// PatchTypeStrategicMerge is a strategic merge patch.
+	PatchTypeStrategicMerge = "strategicMerge"
+
+	// PatchTypeJson6902 is a JSON patch, as specified by RFC 6902.
+	PatchTypeJson6902 = "json6902"
+
+	// PatchTypeMerge is a JSON merge patch, as specified by RFC 7386.
+	// It has no directives or merge keys, so it suits custom
+	// resources: null deletes a field, maps merge and lists replace.
+	PatchTypeMerge = "merge"
+)
+
 // Patch represent either a Strategic Merge Patch or a JSON patch
 // and its targets.
 // The content of the patch can either be from a file
 // or from an inline string.
 type Patch struct {
 	// Path is a relative file path to the patch file.
+	// It may also be a glob, e.g. patches/*.yaml, or a directory,
+	// naming all the files directly within it.  Each document in
+	// a file holding several is a separate patch.
 	Path string `json:"path,omitempty" yaml:"path,omitempty"`
 
 	// Patch is the content of a patch.
@@ -19,7 +45,13 @@ type Patch struct {
 	// Target points to the resources that the patch is applied to
 	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
 
-	// Options is a list of options for the patch
+	// Type is the type of the patch, one of strategicMerge, json6902
+	// or merge.  If empty, it's inferred from the content of the patch,
+	// which is never taken to be a merge patch.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Options is a list of options for the patch, e.g.
+	// allowNameChange, allowKindChange, failIfNoMatch or optional.
 	Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
@@ -29,6 +61,17 @@ func (p *Patch) Equals(o Patch) bool {
 		(p.Target != nil && o.Target != nil && *p.Target == *o.Target)
 	return p.Path == o.Path &&
 		p.Patch == o.Patch &&
+		p.Type == o.Type &&
 		targetEqual &&
 		reflect.DeepEqual(p.Options, o.Options)
 }
+
+// FailIfNoMatch returns true if a patch with the options must
+// match at least one resource, given the build wide PatchConfig.
+func FailIfNoMatch(options map[string]bool, c *PluginConfig) bool {
+	if options[PatchOptionOptional] {
+		return false
+	}
+	return options[PatchOptionFailIfNoMatch] ||
+		(c != nil && c.PatchConfig.FailIfNoMatch)
+}
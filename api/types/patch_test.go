@@ -129,3 +129,49 @@ func TestPatchEquals(t *testing.T) {
 		}
 	}
 }
+
+func TestFailIfNoMatch(t *testing.T) {
+	failing := &PluginConfig{PatchConfig: PatchConfig{FailIfNoMatch: true}}
+	testcases := []struct {
+		name    string
+		options map[string]bool
+		config  *PluginConfig
+		expect  bool
+	}{
+		{
+			name:   "default",
+			config: DisabledPluginConfig(),
+			expect: false,
+		},
+		{
+			name:    "per patch",
+			options: map[string]bool{PatchOptionFailIfNoMatch: true},
+			expect:  true,
+		},
+		{
+			name:   "build wide",
+			config: failing,
+			expect: true,
+		},
+		{
+			name:    "optional",
+			options: map[string]bool{PatchOptionOptional: true},
+			config:  failing,
+			expect:  false,
+		},
+		{
+			name: "optional overrides failIfNoMatch",
+			options: map[string]bool{
+				PatchOptionOptional:      true,
+				PatchOptionFailIfNoMatch: true,
+			},
+			expect: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		if tc.expect != FailIfNoMatch(tc.options, tc.config) {
+			t.Fatalf("%s: unexpected result %v", tc.name, !tc.expect)
+		}
+	}
+}
@@ -3,11 +3,33 @@
 
 package types
 
+import "time"
+
 type HelmConfig struct {
 	Enabled bool
 	Command string
 }
 
+// PatchConfig holds build wide options for the patch transformers.
+type PatchConfig struct {
+	// FailIfNoMatch makes it an error for any patch to match
+	// no resources, unless the patch is optional.
+	FailIfNoMatch bool
+}
+
+// DefaultCommandSourceTimeout bounds the run time of
+// generator command sources that don't set a timeout.
+const DefaultCommandSourceTimeout = 30 * time.Second
+
+// ExternalSourcesConfig holds build wide options for the
+// generator sources reading from outside the kustomization,
+// i.e. environment variable and command sources.
+type ExternalSourcesConfig struct {
+	// Enabled allows generators to read environment
+	// variables and run commands.
+	Enabled bool
+}
+
 // PluginConfig holds plugin configuration.
 type PluginConfig struct {
 	// PluginRestrictions distinguishes plugin restrictions.
@@ -21,6 +43,12 @@ type PluginConfig struct {
 
 	// HelmConfig contains metadata needed for allowing and running helm.
 	HelmConfig HelmConfig
+
+	// PatchConfig contains options for applying patches.
+	PatchConfig PatchConfig
+
+	// ExternalSourcesConfig contains options for generator sources.
+	ExternalSourcesConfig ExternalSourcesConfig
 }
 
 func EnabledPluginConfig(b BuiltinPluginLoadingOptions) (pc *PluginConfig) {
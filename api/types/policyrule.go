@@ -0,0 +1,64 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// PolicyRule declares requirements that the resources selected
+// by Target must satisfy.  It's used by the PolicyValidator.
+type PolicyRule struct {
+	// Name identifies the rule in violation messages.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Target selects the resources the rule applies to.
+	// If not set, the rule applies to all resources.
+	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// RequiredLabels are label keys every selected resource must have.
+	RequiredLabels []string `json:"requiredLabels,omitempty" yaml:"requiredLabels,omitempty"`
+
+	// RequiredAnnotations are annotation keys every selected
+	// resource must have.
+	RequiredAnnotations []string `json:"requiredAnnotations,omitempty" yaml:"requiredAnnotations,omitempty"`
+
+	// ForbiddenFields are fields selected resources must not set.
+	ForbiddenFields []ForbiddenField `json:"forbiddenFields,omitempty" yaml:"forbiddenFields,omitempty"`
+
+	// AllowedImageRegistries, if not empty, are the registries (or
+	// registry/repository prefixes, e.g. gcr.io/my-project) that the
+	// images of all containers in selected resources must come from.
+	// Images without a registry are from docker.io.
+	AllowedImageRegistries []string `json:"allowedImageRegistries,omitempty" yaml:"allowedImageRegistries,omitempty"`
+
+	// RequiredResourceLimits are the resource names, e.g. cpu or
+	// memory, which all containers in selected resources must limit.
+	RequiredResourceLimits []string `json:"requiredResourceLimits,omitempty" yaml:"requiredResourceLimits,omitempty"`
+
+	// Expressions must all be true for selected resources.
+	Expressions []PolicyExpression `json:"expressions,omitempty" yaml:"expressions,omitempty"`
+}
+
+// PolicyExpression is a CEL expression (https://github.com/google/cel-spec)
+// over a resource, which is available as the variable object.
+type PolicyExpression struct {
+	// Expression must evaluate to true, e.g.
+	// object.spec.replicas <= 10
+	// Evaluation errors, e.g. from accessing a field the resource
+	// doesn't have, are violations too.
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`
+
+	// Message describes the violation when the expression is false.
+	// It defaults to the expression.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// ForbiddenField is a field that must not be set.
+type ForbiddenField struct {
+	// Path is a kustomize style path to the field, e.g.
+	// spec/template/spec/hostNetwork.  Paths descend into
+	// every element of lists along the way.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Value, if set, forbids only this value of the field,
+	// e.g. "true".  Otherwise any value is forbidden.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// SchemaValidation controls whether the build output is checked
+// against the OpenAPI schema, and what happens to violations.
+//
+//go:generate stringer -type=SchemaValidation
+type SchemaValidation int
+
+const (
+	// The build output isn't validated.
+	SchemaValidationNone SchemaValidation = iota
+
+	// Schema violations fail the build.
+	SchemaValidationStrict
+
+	// Schema violations are logged as warnings.
+	SchemaValidationWarn
+)
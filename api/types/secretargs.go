@@ -11,9 +11,25 @@ type SecretArgs struct {
 	// Type of the secret.
 	//
 	// This is the same field as the secret type field in v1/Secret:
-	// It can be "Opaque" (default), or "kubernetes.io/tls".
+	// It can be "Opaque" (default), "kubernetes.io/tls" or
+	// "kubernetes.io/dockerconfigjson".
 	//
 	// If type is "kubernetes.io/tls", then "literals" or "files" must have exactly two
-	// keys: "tls.key" and "tls.crt"
+	// keys: "tls.key" and "tls.crt", holding a PEM encoded certificate and its key.
+	//
+	// If type is "kubernetes.io/dockerconfigjson", then either "registries"
+	// must be set, or the sources must have the key ".dockerconfigjson".
 	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Registries whose credentials make up the ".dockerconfigjson"
+	// key of a Secret of type "kubernetes.io/dockerconfigjson".
+	Registries []DockerRegistry `json:"registries,omitempty" yaml:"registries,omitempty"`
+
+	// Encrypted sources of the secret's data, decrypted
+	// when it's generated.  See EncryptedSources.
+	Encrypted *EncryptedSources `json:"encrypted,omitempty" yaml:"encrypted,omitempty"`
+
+	// Output says how to emit the secret, e.g. sealed.
+	// A build wide output overrides it.  See SecretOutput.
+	Output *SecretOutput `json:"output,omitempty" yaml:"output,omitempty"`
 }
@@ -0,0 +1,112 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "fmt"
+
+// SecretOutputFormat says how a generated Secret is emitted.
+type SecretOutputFormat string
+
+const (
+	// SecretOutputData base64 encodes the values
+	// into the data field; the default.
+	SecretOutputData SecretOutputFormat = "data"
+	// SecretOutputStringData puts the values, if UTF-8,
+	// in clear into the stringData field, e.g. for review.
+	SecretOutputStringData SecretOutputFormat = "stringData"
+	// SecretOutputSealed emits a SealedSecret, its values
+	// encrypted for a sealed secrets controller, in place
+	// of the Secret, e.g. for GitOps.
+	SecretOutputSealed SecretOutputFormat = "sealed"
+)
+
+var secretOutputFormats = []SecretOutputFormat{
+	SecretOutputData,
+	SecretOutputStringData,
+	SecretOutputSealed,
+}
+
+// SealingScope is the scope of a SealedSecret, as
+// understood by the sealed secrets controller.
+type SealingScope string
+
+const (
+	// SealingScopeStrict binds the values to the name
+	// and namespace of the Secret; the default.
+	SealingScopeStrict SealingScope = "strict"
+	// SealingScopeNamespaceWide binds them to the namespace.
+	SealingScopeNamespaceWide SealingScope = "namespace-wide"
+	// SealingScopeClusterWide doesn't bind them.
+	SealingScopeClusterWide SealingScope = "cluster-wide"
+)
+
+var sealingScopes = []SealingScope{
+	SealingScopeStrict,
+	SealingScopeNamespaceWide,
+	SealingScopeClusterWide,
+}
+
+// SecretOutputAnnotation holds, on a generated Secret, the output
+// its generator asked for, with the certificate loaded, until the
+// Secret is emitted at the end of the build.
+const SecretOutputAnnotation = "kustomize.config.k8s.io/secret-output"
+
+// SecretOutput says how to emit a generated Secret.  It's applied
+// to the Secret as it is at the end of the build, i.e. with its
+// final name and namespace.
+type SecretOutput struct {
+	// Format of the output; defaults to data.
+	Format SecretOutputFormat `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Certificate is the path to the PEM encoded certificate, or
+	// public key, of the sealed secrets controller.  The sealed
+	// format requires it.
+	Certificate string `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+
+	// Scope of the SealedSecret; defaults to strict.
+	Scope SealingScope `json:"scope,omitempty" yaml:"scope,omitempty"`
+}
+
+// Validate returns an error if the format or
+// scope is unknown, or a certificate is missing.
+func (o *SecretOutput) Validate() error {
+	if !containsSecretOutputFormat(o.Format) {
+		return fmt.Errorf("unknown secret output format '%s'; must be one of %v",
+			o.Format, secretOutputFormats)
+	}
+	if o.Format != SecretOutputSealed {
+		if o.Certificate != "" || o.Scope != "" {
+			return fmt.Errorf(
+				"certificate and scope require the %s secret output format",
+				SecretOutputSealed)
+		}
+		return nil
+	}
+	if o.Certificate == "" {
+		return fmt.Errorf("the %s secret output format requires a certificate",
+			SecretOutputSealed)
+	}
+	if o.Scope == "" {
+		return nil
+	}
+	for _, known := range sealingScopes {
+		if o.Scope == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown sealing scope '%s'; must be one of %v",
+		o.Scope, sealingScopes)
+}
+
+func containsSecretOutputFormat(f SecretOutputFormat) bool {
+	if f == "" {
+		return true
+	}
+	for _, known := range secretOutputFormats {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
@@ -26,11 +26,38 @@ type Selector struct {
 	// https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#api
 	// It matches with the resource labels.
 	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+
+	// Expression is a CEL expression (https://github.com/google/cel-spec)
+	// over the resource, which is available as the variable object, e.g.
+	// object.spec.replicas > 1 && has(object.spec.template.spec.nodeSelector)
+	// It matches when it evaluates to true.  Evaluation errors, e.g. from
+	// accessing a field the resource doesn't have, don't match.
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`
+
+	// FieldSelector is a comma separated list of conditions on the
+	// values of fields of the resource, which must all hold, e.g.
+	// spec.type=LoadBalancer,spec.ports.[name=http].port~80|8080
+	// Each condition starts with the dot delimited path to a field,
+	// which may select list elements as in [name=http], and is one of
+	//   path=value  the field is a scalar equal to the value, maybe empty
+	//   path~regex  the field is a scalar the regex wholly matches
+	//   path        the field exists
+	//   !path       the field is absent; a null field is absent
+	// A backslash escapes the character after it, e.g. a comma
+	// in a value or a dot in a path element.
+	FieldSelector string `json:"fieldSelector,omitempty" yaml:"fieldSelector,omitempty"`
 }
 
 func (s *Selector) String() string {
-	return fmt.Sprintf(
+	result := fmt.Sprintf(
 		"%s:a=%s:l=%s", s.ResId, s.AnnotationSelector, s.LabelSelector)
+	if s.Expression != "" {
+		result += ":e=" + s.Expression
+	}
+	if s.FieldSelector != "" {
+		result += ":f=" + s.FieldSelector
+	}
+	return result
 }
 
 // SelectorRegex is a Selector with regex in GVK
@@ -0,0 +1,31 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "sigs.k8s.io/kustomize/kyaml/resid"
+
+// Tie-breakers ordering resources matching the same pattern.
+const (
+	SortByNamespace = "namespace"
+	SortByName      = "name"
+)
+
+// SortOptions configure the order of the resources
+// put out by the SortTransformer.
+type SortOptions struct {
+	// First are patterns of the resources to put first, in the
+	// order of the patterns.  In a pattern, empty fields match
+	// anything, e.g. {kind: Namespace} matches all Namespaces.
+	First []resid.Gvk `json:"first,omitempty" yaml:"first,omitempty"`
+
+	// Last are patterns of the resources to put last, after
+	// those matching no pattern, in the order of the patterns.
+	// A resource matching a pattern in First isn't put last.
+	Last []resid.Gvk `json:"last,omitempty" yaml:"last,omitempty"`
+
+	// TieBreakers order the resources matching the same pattern,
+	// or none, by namespace or name, in the given order of
+	// precedence.  Remaining ties keep their input order.
+	TieBreakers []string `json:"tieBreakers,omitempty" yaml:"tieBreakers,omitempty"`
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Formats of structured sources.
+const (
+	StructuredFormatJSON       = "json"
+	StructuredFormatYAML       = "yaml"
+	StructuredFormatTOML       = "toml"
+	StructuredFormatProperties = "properties"
+)
+
+// StructuredSource is a JSON, YAML, TOML or Java properties file
+// whose values become key value pairs.  By default, each leaf value
+// becomes a pair, keyed by the path to it, e.g. the host in
+//   db:
+//     host: localhost
+// becomes db.host=localhost.  If Subtrees is set, only the selected
+// values become pairs, serialized in the format of the file.
+type StructuredSource struct {
+	// Path to the file.
+	Path string `json:"path" yaml:"path"`
+
+	// Format of the file, one of json, yaml, toml or properties.
+	// Defaults to the format its extension names, e.g. .yml is yaml.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// KeySeparator joins the map keys and list indices on the path
+	// to a value into its key.  Defaults to ".".  The keys of
+	// properties files are used as they are.
+	KeySeparator string `json:"keySeparator,omitempty" yaml:"keySeparator,omitempty"`
+
+	// Subtrees, if not empty, select the values to use.
+	Subtrees []StructuredSubtree `json:"subtrees,omitempty" yaml:"subtrees,omitempty"`
+}
+
+// StructuredSubtree selects a value of a StructuredSource.
+type StructuredSubtree struct {
+	// Path to the value, written like the keys of leaf values.
+	Path string `json:"path" yaml:"path"`
+
+	// Key of the value; defaults to the path.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+}
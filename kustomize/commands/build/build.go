@@ -8,6 +8,7 @@ import (
 	"io"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/krusty"
@@ -21,14 +22,26 @@ var theArgs struct {
 var theFlags struct {
 	outputPath string
 	enable     struct {
-		plugins        bool
-		managedByLabel bool
-		helm           bool
+		plugins         bool
+		managedByLabel  bool
+		helm            bool
+		externalSources bool
+	}
+	helmCommand        string
+	loadRestrictor     string
+	reorderOutput      string
+	validate           string
+	failIfPatchNoMatch bool
+	components         []string
+	withoutComponents  []string
+	include            []string
+	exclude            []string
+	fnOptions          types.FnPluginLoadingOptions
+	secretOutput       struct {
+		format      string
+		certificate string
+		scope       string
 	}
-	helmCommand    string
-	loadRestrictor string
-	reorderOutput  string
-	fnOptions      types.FnPluginLoadingOptions
 }
 
 type Help struct {
@@ -99,15 +112,28 @@ func NewCmdBuild(
 		},
 	}
 	AddFlagOutputPath(cmd.Flags())
-	AddFunctionBasicsFlags(cmd.Flags())
-	AddFlagLoadRestrictor(cmd.Flags())
-	AddFlagEnablePlugins(cmd.Flags())
-	AddFlagReorderOutput(cmd.Flags())
-	AddFlagEnableManagedbyLabel(cmd.Flags())
-	AddFlagEnableHelm(cmd.Flags())
+	AddBuildFlags(cmd.Flags())
 	return cmd
 }
 
+// AddBuildFlags adds the flags configuring the build, i.e. all the
+// flags of the build command but --output, for other commands
+// building kustomizations.  HonorKustomizeFlags applies them.
+func AddBuildFlags(set *pflag.FlagSet) {
+	AddFunctionBasicsFlags(set)
+	AddFlagLoadRestrictor(set)
+	AddFlagEnablePlugins(set)
+	AddFlagReorderOutput(set)
+	AddFlagEnableManagedbyLabel(set)
+	AddFlagEnableHelm(set)
+	AddFlagEnableExternalSources(set)
+	AddFlagSecretOutput(set)
+	AddFlagValidate(set)
+	AddFlagFailIfPatchNoMatch(set)
+	AddFlagComponents(set)
+	AddFlagSelectors(set)
+}
+
 // Validate validates build command args and flags.
 func Validate(args []string) error {
 	if len(args) > 1 {
@@ -120,9 +146,23 @@ func Validate(args []string) error {
 	} else {
 		theArgs.kustomizationPath = args[0]
 	}
+	return ValidateBuildFlags()
+}
+
+// ValidateBuildFlags validates the flags added by AddBuildFlags.
+func ValidateBuildFlags() error {
 	if err := validateFlagLoadRestrictor(); err != nil {
 		return err
 	}
+	if err := validateFlagValidate(); err != nil {
+		return err
+	}
+	if err := validateFlagSelectors(); err != nil {
+		return err
+	}
+	if err := validateFlagSecretOutput(); err != nil {
+		return err
+	}
 	return validateFlagReorderOutput()
 }
 
@@ -130,6 +170,7 @@ func Validate(args []string) error {
 // Flags and such are held in private package variables.
 func HonorKustomizeFlags(kOpts *krusty.Options) *krusty.Options {
 	kOpts.DoLegacyResourceSort = getFlagReorderOutput() == legacy
+	kOpts.DoCustomResourceSort = getFlagReorderOutput() == custom
 	kOpts.LoadRestrictions = getFlagLoadRestrictorValue()
 	if theFlags.enable.plugins {
 		c := types.EnabledPluginConfig(types.BploUseStaticallyLinked)
@@ -139,6 +180,14 @@ func HonorKustomizeFlags(kOpts *krusty.Options) *krusty.Options {
 		kOpts.PluginConfig.HelmConfig.Enabled = theFlags.enable.helm
 	}
 	kOpts.PluginConfig.HelmConfig.Command = theFlags.helmCommand
+	kOpts.PluginConfig.PatchConfig.FailIfNoMatch = theFlags.failIfPatchNoMatch
+	kOpts.PluginConfig.ExternalSourcesConfig.Enabled = theFlags.enable.externalSources
 	kOpts.AddManagedbyLabel = isManagedByLabelEnabled()
+	kOpts.SchemaValidation = getFlagValidateValue()
+	kOpts.Components = theFlags.components
+	kOpts.WithoutComponents = theFlags.withoutComponents
+	kOpts.Include = getFlagSelectors(theFlags.include)
+	kOpts.Exclude = getFlagSelectors(theFlags.exclude)
+	kOpts.SecretOutput = getFlagSecretOutput()
 	return kOpts
 }
@@ -197,6 +197,26 @@ type: Opaque
 	}
 }
 
+func TestBuildWithSchemaValidation(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	if err := cmd.ParseFlags([]string{"--validate"}); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Flags().Set("validate", "none")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil {
+		t.Fatal("expected schema validation error")
+	}
+	expected := `1 schema violation(s):
+  apps_v1_Deployment|ns1|foo-dply1-bar: spec.replica: unknown field`
+	if err.Error() != expected {
+		t.Fatalf("Expected error:\n%s\nBut got:\n%s", expected, err)
+	}
+}
+
 func TestHelp(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	buffy := new(bytes.Buffer)
@@ -0,0 +1,26 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagComponents adds the --component and --without-component
+// flags, which add components to, or drop them from, the
+// kustomization being built without editing it.
+func AddFlagComponents(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.components,
+		"component",
+		[]string{},
+		"Path of a component to add to the components of the kustomization, "+
+			"relative to its directory.  May be repeated.")
+	set.StringArrayVar(
+		&theFlags.withoutComponents,
+		"without-component",
+		[]string{},
+		"Path of a component to drop from the components of the kustomization, "+
+			"as listed in its file.  May be repeated.")
+}
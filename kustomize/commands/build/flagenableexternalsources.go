@@ -0,0 +1,19 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagEnableExternalSources adds the --enable-external-sources flag.
+// Like --enable-helm, it's independent of --enable-alpha-plugins.
+func AddFlagEnableExternalSources(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.externalSources,
+		"enable-external-sources",
+		false,
+		"Enable configMap and secret generator sources that read environment "+
+			"variables or run commands; do not use for untrusted configs!")
+}
@@ -0,0 +1,18 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// AddFlagFailIfPatchNoMatch adds the --fail-if-patch-no-match flag.
+// Patches marked optional are exempt.
+func AddFlagFailIfPatchNoMatch(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.failIfPatchNoMatch,
+		"fail-if-patch-no-match",
+		false,
+		"Fail if a patch matches no resources, unless the patch is optional.")
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const flagSecretOutputName = "secret-output"
+
+// AddFlagSecretOutput adds the --secret-output flag, and
+// the --sealing-cert and --sealing-scope flags it uses.
+func AddFlagSecretOutput(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.secretOutput.format, flagSecretOutputName, "",
+		"Emit all generated secrets with '"+string(types.SecretOutputData)+
+			"', '"+string(types.SecretOutputStringData)+"' (in clear, for "+
+			"review), or '"+string(types.SecretOutputSealed)+"' (as "+
+			"SealedSecrets), overriding the outputs of their generators.")
+	set.StringVar(
+		&theFlags.secretOutput.certificate, "sealing-cert", "",
+		"Path to the certificate of the sealed secrets controller, "+
+			"for --"+flagSecretOutputName+"="+string(types.SecretOutputSealed)+".")
+	set.StringVar(
+		&theFlags.secretOutput.scope, "sealing-scope", "",
+		"Scope of the emitted SealedSecrets: '"+
+			string(types.SealingScopeStrict)+"' (the default), '"+
+			string(types.SealingScopeNamespaceWide)+"' or '"+
+			string(types.SealingScopeClusterWide)+"'.")
+}
+
+func validateFlagSecretOutput() error {
+	o := getFlagSecretOutput()
+	if o == nil {
+		if theFlags.secretOutput.certificate != "" ||
+			theFlags.secretOutput.scope != "" {
+			return fmt.Errorf(
+				"--sealing-cert and --sealing-scope require --%s=%s",
+				flagSecretOutputName, types.SecretOutputSealed)
+		}
+		return nil
+	}
+	if err := o.Validate(); err != nil {
+		return fmt.Errorf("illegal flag value --%s %s: %v",
+			flagSecretOutputName, theFlags.secretOutput.format, err)
+	}
+	return nil
+}
+
+func getFlagSecretOutput() *types.SecretOutput {
+	if theFlags.secretOutput.format == "" {
+		return nil
+	}
+	return &types.SecretOutput{
+		Format:      types.SecretOutputFormat(theFlags.secretOutput.format),
+		Certificate: theFlags.secretOutput.certificate,
+		Scope:       types.SealingScope(theFlags.secretOutput.scope),
+	}
+}
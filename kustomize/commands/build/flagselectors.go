@@ -0,0 +1,101 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagIncludeName = "include"
+	flagExcludeName = "exclude"
+)
+
+// AddFlagSelectors adds the --include and --exclude flags,
+// which filter the resources put out.
+func AddFlagSelectors(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.include,
+		flagIncludeName,
+		[]string{},
+		"Only put out resources matching this selector, or another "+
+			"--include selector.  A selector is a comma separated list of "+
+			"group, version, kind, name, namespace, labelSelector or "+
+			"annotationSelector=value, where the values of the first five "+
+			"are regular expressions matching the whole field, not globs: "+
+			"'kind=Role' matches Role but not RoleBinding, and "+
+			"'kind=Role.*' matches both, e.g. 'kind=Role.*,namespace=prod'.")
+	set.StringArrayVar(
+		&theFlags.exclude,
+		flagExcludeName,
+		[]string{},
+		"Don't put out resources matching this selector, "+
+			"even if they match an --include selector.  May be repeated.")
+}
+
+func validateFlagSelectors() error {
+	for _, s := range theFlags.include {
+		if _, err := parseSelector(s); err != nil {
+			return fmt.Errorf("invalid --%s: %w", flagIncludeName, err)
+		}
+	}
+	for _, s := range theFlags.exclude {
+		if _, err := parseSelector(s); err != nil {
+			return fmt.Errorf("invalid --%s: %w", flagExcludeName, err)
+		}
+	}
+	return nil
+}
+
+func getFlagSelectors(values []string) []types.Selector {
+	var result []types.Selector
+	for _, v := range values {
+		// validated before
+		s, _ := parseSelector(v)
+		result = append(result, *s)
+	}
+	return result
+}
+
+// parseSelector parses a comma separated list of key=value pairs.
+// A part not starting with a known key continues the value before,
+// so label selectors may hold commas.
+func parseSelector(value string) (*types.Selector, error) {
+	result := &types.Selector{}
+	fields := map[string]*string{
+		"group":              &result.Group,
+		"version":            &result.Version,
+		"kind":               &result.Kind,
+		"name":               &result.Name,
+		"namespace":          &result.Namespace,
+		"labelSelector":      &result.LabelSelector,
+		"annotationSelector": &result.AnnotationSelector,
+	}
+	var field *string
+	for _, part := range strings.Split(value, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if f, known := fields[kv[0]]; known && len(kv) == 2 {
+			if *f != "" {
+				return nil, fmt.Errorf("'%s' has %s twice", value, kv[0])
+			}
+			field = f
+			*field = kv[1]
+			continue
+		}
+		if field == nil {
+			return nil, fmt.Errorf(
+				"'%s' must start with one of group, version, kind, "+
+					"name, namespace, labelSelector or annotationSelector=", value)
+		}
+		*field += "," + part
+	}
+	if _, err := types.NewSelectorRegex(result); err != nil {
+		return nil, fmt.Errorf("'%s': %w", value, err)
+	}
+	return result, nil
+}
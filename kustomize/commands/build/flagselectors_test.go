@@ -0,0 +1,68 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+func TestParseSelector(t *testing.T) {
+	testCases := map[string]struct {
+		value    string
+		expected types.Selector
+		err      string
+	}{
+		"kind": {
+			value: "kind=Role.*",
+			expected: types.Selector{
+				ResId: resid.ResId{Gvk: resid.Gvk{Kind: "Role.*"}}},
+		},
+		"several fields": {
+			value: "group=apps,kind=Deployment,namespace=prod,name=web",
+			expected: types.Selector{
+				ResId: resid.ResId{
+					Gvk:       resid.Gvk{Group: "apps", Kind: "Deployment"},
+					Name:      "web",
+					Namespace: "prod",
+				}},
+		},
+		"label selector with commas": {
+			value: "labelSelector=app=web,tier in (front,back),kind=Service",
+			expected: types.Selector{
+				ResId:         resid.ResId{Gvk: resid.Gvk{Kind: "Service"}},
+				LabelSelector: "app=web,tier in (front,back)",
+			},
+		},
+		"unknown key": {
+			value: "color=blue",
+			err:   "'color=blue' must start with one of group",
+		},
+		"repeated key": {
+			value: "kind=Role,kind=ClusterRole",
+			err:   "'kind=Role,kind=ClusterRole' has kind twice",
+		},
+		"invalid regex": {
+			value: "name=(",
+			err:   "'name=(': error parsing regexp",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s, err := parseSelector(tc.value)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, *s)
+			}
+		})
+	}
+}
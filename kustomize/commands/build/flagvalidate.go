@@ -0,0 +1,53 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagValidateName = "validate"
+	validateNone     = "none"
+	validateStrict   = "strict"
+	validateWarn     = "warn"
+)
+
+func AddFlagValidate(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.validate, flagValidateName,
+		validateNone,
+		"Validate the output against the OpenAPI schema, reporting "+
+			"unknown fields, type mismatches and missing required fields. "+
+			"Use '"+validateStrict+"' (the default if the flag is given "+
+			"without a value) to fail the build on violations, "+
+			"or '"+validateWarn+"' to only print them.")
+	set.Lookup(flagValidateName).NoOptDefVal = validateStrict
+}
+
+func validateFlagValidate() error {
+	switch theFlags.validate {
+	case validateNone, validateStrict, validateWarn:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagValidateName, theFlags.validate,
+			[]string{validateNone, validateStrict, validateWarn})
+	}
+}
+
+func getFlagValidateValue() types.SchemaValidation {
+	switch theFlags.validate {
+	case validateStrict:
+		return types.SchemaValidationStrict
+	case validateWarn:
+		return types.SchemaValidationWarn
+	default:
+		return types.SchemaValidationNone
+	}
+}
@@ -16,6 +16,7 @@ const (
 	unspecified reorderOutput = iota
 	none
 	legacy
+	custom
 )
 
 const flagReorderOutputName = "reorder"
@@ -27,18 +28,20 @@ func AddFlagReorderOutput(set *pflag.FlagSet) {
 		"Reorder the resources just before output. "+
 			"Use '"+legacy.String()+"' to apply a legacy reordering "+
 			"(Namespaces first, Webhooks last, etc). "+
-			"Use '"+none.String()+"' to suppress a final reordering.")
+			"Use '"+none.String()+"' to suppress a final reordering. "+
+			"Use '"+custom.String()+"' to reorder per the sortOptions "+
+			"of the kustomization.")
 }
 
 func validateFlagReorderOutput() error {
 	switch theFlags.reorderOutput {
-	case none.String(), legacy.String():
+	case none.String(), legacy.String(), custom.String():
 		return nil
 	default:
 		return fmt.Errorf(
 			"illegal flag value --%s %s; legal values: %v",
 			flagReorderOutputName, theFlags.reorderOutput,
-			[]string{legacy.String(), none.String()})
+			[]string{legacy.String(), none.String(), custom.String()})
 	}
 }
 
@@ -48,6 +51,8 @@ func getFlagReorderOutput() reorderOutput {
 		return none
 	case legacy.String():
 		return legacy
+	case custom.String():
+		return custom
 	default:
 		return unspecified
 	}
@@ -19,7 +19,7 @@ func newCmdAddSecret(
 	rf *resource.Factory) *cobra.Command {
 	var flags flagsAndArgs
 	cmd := &cobra.Command{
-		Use:   "secret NAME [--from-file=[key=]source] [--from-literal=key1=value1] [--type=Opaque|kubernetes.io/tls]",
+		Use:   "secret NAME [--from-file=[key=]source] [--from-literal=key1=value1] [--type=Opaque|kubernetes.io/tls|kubernetes.io/dockerconfigjson]",
 		Short: "Adds a secret to the kustomization file.",
 		Long:  "",
 		Example: `
@@ -86,7 +86,7 @@ func newCmdAddSecret(
 		&flags.Type,
 		"type",
 		"Opaque",
-		"Specify the secret type this can be 'Opaque' (default), or 'kubernetes.io/tls'")
+		"Specify the secret type this can be 'Opaque' (default), 'kubernetes.io/tls' or 'kubernetes.io/dockerconfigjson'")
 	cmd.Flags().StringVar(
 		&flags.Namespace,
 		"namespace",
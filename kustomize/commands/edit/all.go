@@ -11,6 +11,7 @@ import (
 	"sigs.k8s.io/kustomize/api/loader"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/add"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/derivepatch"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/fix"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/listbuiltin"
 	"sigs.k8s.io/kustomize/kustomize/v4/commands/edit/remove"
@@ -33,6 +34,9 @@ func NewCmdEdit(
 
 	# Sets the namesuffix field
 	kustomize edit set namesuffix <suffix-value>
+
+	# Adds patches turning the build output into a desired manifest
+	kustomize edit derive-patch <desired-manifest-file>
 `,
 		Args: cobra.MinimumNArgs(1),
 	}
@@ -47,6 +51,7 @@ func NewCmdEdit(
 			kv.NewLoader(loader.NewFileLoaderAtCwd(fSys), v),
 			v),
 		fix.NewCmdFix(fSys),
+		derivepatch.NewCmdDerivePatch(fSys),
 		remove.NewCmdRemove(fSys, v),
 		listbuiltin.NewCmdListBuiltinPlugin(),
 	)
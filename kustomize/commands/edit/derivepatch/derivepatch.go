@@ -0,0 +1,299 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package derivepatch
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/filters/patchstrategicmerge"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/build"
+	"sigs.k8s.io/kustomize/kustomize/v4/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/diff2"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// NewCmdDerivePatch returns an instance of 'derive-patch' subcommand.
+func NewCmdDerivePatch(fSys filesys.FileSystem) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "derive-patch {desiredManifestFile}",
+		Short: "Adds patches turning the build output into a desired manifest",
+		Long: `Builds the kustomization in the current directory, compares the
+output with the resources in the desired manifest, and adds a patch for
+each changed resource to the patches field, so that building again
+gives the desired manifest.
+
+Each patch is a minimal strategic merge patch, or a JSON 6902 patch if
+a strategic merge patch can't express the changes, e.g. reordering the
+elements of a list.  Resources of the build output missing from the
+manifest are left as they are, but the manifest mustn't have resources
+the build output doesn't.
+
+The build takes the flags of the build command, so that it can give
+the output the desired manifest was made from.
+`,
+		Example: `
+	# hand edit the build output, then derive the patches
+	kustomize build > desired.yaml
+	kustomize edit derive-patch desired.yaml
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("must specify a desired manifest file")
+			}
+			if err := build.ValidateBuildFlags(); err != nil {
+				return err
+			}
+			return runDerivePatch(fSys, args[0],
+				build.HonorKustomizeFlags(krusty.MakeDefaultOptions()))
+		},
+	}
+	// build as the build command would
+	build.AddBuildFlags(cmd.Flags())
+	return cmd
+}
+
+func runDerivePatch(
+	fSys filesys.FileSystem, desiredFile string, opts *krusty.Options) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+	b, err := fSys.ReadFile(desiredFile)
+	if err != nil {
+		return err
+	}
+	desired, err := kio.FromBytes(b)
+	if err != nil {
+		return err
+	}
+
+	// keep the build annotations to target the
+	// resources by the ids they have when patched
+	annotated := *opts
+	annotated.KeepBuildAnnotations = true
+	current, err := krusty.MakeKustomizer(&annotated).Run(fSys, ".")
+	if err != nil {
+		return err
+	}
+	var patches []types.Patch
+	for _, d := range desired {
+		r, err := builtResource(current, d)
+		if err != nil {
+			return err
+		}
+		orgId := r.OrgId()
+		r.RemoveBuildAnnotations()
+		patch, err := derivePatch(&r.RNode, d)
+		if err != nil {
+			return fmt.Errorf(
+				"unable to derive patch for %s: %w", r.CurId(), err)
+		}
+		if patch == "" {
+			continue
+		}
+		patches = append(patches, types.Patch{
+			Patch:  patch,
+			Target: target(orgId),
+		})
+	}
+	if len(patches) == 0 {
+		log.Printf("the build output already matches %s", desiredFile)
+		return nil
+	}
+
+	original := m.Patches
+	m.Patches = append(append([]types.Patch{}, original...), patches...)
+	if err := mf.Write(m); err != nil {
+		return err
+	}
+	if err := checkReproduces(fSys, desired, opts); err != nil {
+		m.Patches = original
+		if restoreErr := mf.Write(m); restoreErr != nil {
+			return restoreErr
+		}
+		return err
+	}
+	return nil
+}
+
+// builtResource returns the resource of the build output
+// with the current id of the desired resource.
+func builtResource(m resmap.ResMap, desired *yaml.RNode) (*resource.Resource, error) {
+	id := resid.NewResIdWithNamespace(
+		resid.GvkFromNode(desired), desired.GetName(), desired.GetNamespace())
+	matches := m.GetMatchingResourcesByCurrentId(id.Equals)
+	if len(matches) != 1 {
+		return nil, fmt.Errorf(
+			"resource %s isn't in the build output; "+
+				"patches can only change existing resources", id)
+	}
+	return matches[0], nil
+}
+
+// target selects exactly the resource with the id.
+func target(id resid.ResId) *types.Selector {
+	result := &types.Selector{
+		ResId: resid.ResId{
+			Gvk:  id.Gvk,
+			Name: regexp.QuoteMeta(id.Name),
+		},
+	}
+	if !id.IsClusterScoped() {
+		result.Namespace = regexp.QuoteMeta(id.EffectiveNamespace())
+	}
+	return result
+}
+
+// derivePatch returns a patch changing current into desired, or
+// the empty string if they're equal.  It's a strategic merge patch
+// if the one derived gives desired, else a JSON 6902 patch.
+func derivePatch(current, desired *yaml.RNode) (string, error) {
+	want, err := desired.Map()
+	if err != nil {
+		return "", err
+	}
+	have, err := current.Map()
+	if err != nil {
+		return "", err
+	}
+	if reflect.DeepEqual(have, want) {
+		return "", nil
+	}
+	smp, err := diff2.Diff(current, desired)
+	if err != nil {
+		return "", err
+	}
+	if smp != nil {
+		merged, err := patchstrategicmerge.Filter{Patch: smp}.Filter(
+			[]*yaml.RNode{current.Copy()})
+		if err != nil {
+			return "", err
+		}
+		var got map[string]interface{}
+		if len(merged) == 1 {
+			if got, err = merged[0].Map(); err != nil {
+				return "", err
+			}
+		}
+		if reflect.DeepEqual(got, want) {
+			return smp.String()
+		}
+	}
+	b, err := sigsyaml.Marshal(jsonPatch("", have, want))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// jsonPatch returns the JSON 6902 operations changing the value
+// at the path from current to desired.  Lists are replaced as a
+// whole unless they have the same length.
+func jsonPatch(path string, current, desired interface{}) []map[string]interface{} {
+	var ops []map[string]interface{}
+	switch c := current.(type) {
+	case map[string]interface{}:
+		d, ok := desired.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, k := range sortedKeys(c) {
+			if _, found := d[k]; !found {
+				ops = append(ops, map[string]interface{}{
+					"op": "remove", "path": path + "/" + escape(k)})
+			}
+		}
+		for _, k := range sortedKeys(d) {
+			if v, found := c[k]; found {
+				ops = append(ops, jsonPatch(path+"/"+escape(k), v, d[k])...)
+			} else {
+				ops = append(ops, map[string]interface{}{
+					"op": "add", "path": path + "/" + escape(k), "value": d[k]})
+			}
+		}
+		return ops
+	case []interface{}:
+		d, ok := desired.([]interface{})
+		if !ok || len(c) != len(d) {
+			break
+		}
+		for i := range c {
+			ops = append(ops, jsonPatch(fmt.Sprintf("%s/%d", path, i), c[i], d[i])...)
+		}
+		return ops
+	}
+	if reflect.DeepEqual(current, desired) {
+		return nil
+	}
+	return []map[string]interface{}{
+		{"op": "replace", "path": path, "value": desired}}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escape escapes a field name for use in a JSON pointer.
+func escape(field string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(field, "~", "~0"), "/", "~1")
+}
+
+// checkReproduces builds the kustomization again, and
+// fails unless the output has the desired resources.
+func checkReproduces(
+	fSys filesys.FileSystem, desired []*yaml.RNode, opts *krusty.Options) error {
+	m, err := krusty.MakeKustomizer(opts).Run(fSys, ".")
+	if err != nil {
+		return err
+	}
+	var different []string
+	for _, d := range desired {
+		r, err := builtResource(m, d)
+		if err != nil {
+			return err
+		}
+		want, err := d.Map()
+		if err != nil {
+			return err
+		}
+		got, err := r.Map()
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(got, want) {
+			different = append(different, r.CurId().String())
+		}
+	}
+	if len(different) > 0 {
+		return fmt.Errorf(
+			"later transformations undo the patches of %s; "+
+				"the kustomization is unchanged",
+			strings.Join(different, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package derivepatch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	testutils_test "sigs.k8s.io/kustomize/kustomize/v4/commands/internal/testutils"
+)
+
+const (
+	kustomization = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: dev-
+commonLabels:
+  env: dev
+resources:
+- resources.yaml
+`
+	resources = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.19
+      - name: sidecar
+        image: sidecar:1.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`
+)
+
+func writeKustomization(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(kustomization))
+	assert.NoError(t, fSys.WriteFile("resources.yaml", []byte(resources)))
+	return fSys
+}
+
+func buildOutput(t *testing.T, fSys filesys.FileSystem) string {
+	t.Helper()
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, ".")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	b, err := m.AsYaml()
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestDerivePatch(t *testing.T) {
+	testCases := map[string]struct {
+		edit func(string) string
+		// expected are the patches added to the kustomization
+		expected string
+	}{
+		"strategic merge patch": {
+			edit: func(s string) string {
+				s = strings.Replace(s, "replicas: 1", "replicas: 3", 1)
+				return strings.Replace(s, "nginx:1.19", "nginx:1.21", 1)
+			},
+			expected: `patches:
+- patch: |
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: dev-web
+    spec:
+      replicas: 3
+      template:
+        spec:
+          containers:
+            - image: nginx:1.21
+              name: nginx
+  target:
+    group: apps
+    kind: Deployment
+    name: web
+    namespace: default
+    version: v1
+`,
+		},
+		"json patch": {
+			edit: func(s string) string {
+				return strings.Replace(s, `      - image: nginx:1.19
+        name: nginx
+      - image: sidecar:1.0
+        name: sidecar
+`, `      - image: sidecar:1.0
+        name: sidecar
+      - image: nginx:1.19
+        name: nginx
+`, 1)
+			},
+			expected: `patches:
+- patch: |
+    - op: replace
+      path: /spec/template/spec/containers/0/image
+      value: sidecar:1.0
+    - op: replace
+      path: /spec/template/spec/containers/0/name
+      value: sidecar
+    - op: replace
+      path: /spec/template/spec/containers/1/image
+      value: nginx:1.19
+    - op: replace
+      path: /spec/template/spec/containers/1/name
+      value: nginx
+  target:
+    group: apps
+    kind: Deployment
+    name: web
+    namespace: default
+    version: v1
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fSys := writeKustomization(t)
+			desired := tc.edit(buildOutput(t, fSys))
+			assert.NoError(t, fSys.WriteFile("desired.yaml", []byte(desired)))
+
+			cmd := NewCmdDerivePatch(fSys)
+			cmd.SetArgs([]string{"desired.yaml"})
+			if !assert.NoError(t, cmd.Execute()) {
+				t.FailNow()
+			}
+			content, err := testutils_test.ReadTestKustomization(fSys)
+			assert.NoError(t, err)
+			assert.Contains(t, string(content), tc.expected)
+			assert.Equal(t, desired, buildOutput(t, fSys))
+		})
+	}
+}
+
+func TestDerivePatchOnlyDesiredResources(t *testing.T) {
+	fSys := writeKustomization(t)
+	output := buildOutput(t, fSys)
+	// leave out the service
+	desired := strings.Replace(
+		output[:strings.Index(output, "---")], "replicas: 1", "replicas: 2", 1)
+	assert.NoError(t, fSys.WriteFile("desired.yaml", []byte(desired)))
+
+	cmd := NewCmdDerivePatch(fSys)
+	cmd.SetArgs([]string{"desired.yaml"})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t,
+		strings.Replace(output, "replicas: 1", "replicas: 2", 1), buildOutput(t, fSys))
+}
+
+func TestDerivePatchErrors(t *testing.T) {
+	testCases := map[string]struct {
+		edit func(string) string
+		err  string
+	}{
+		"new resource": {
+			edit: func(s string) string {
+				return s + `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new
+`
+			},
+			err: "resource ~G_v1_ConfigMap|~X|new isn't in the build output",
+		},
+		"undone by later transformations": {
+			edit: func(s string) string {
+				return strings.Replace(s, "env: dev", "env: prod", 1)
+			},
+			err: "later transformations undo the patches of apps_v1_Deployment|~X|dev-web",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fSys := writeKustomization(t)
+			assert.NoError(t, fSys.WriteFile(
+				"desired.yaml", []byte(tc.edit(buildOutput(t, fSys)))))
+
+			cmd := NewCmdDerivePatch(fSys)
+			cmd.SetArgs([]string{"desired.yaml"})
+			err := cmd.Execute()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+			// the kustomization is unchanged
+			content, err := testutils_test.ReadTestKustomization(fSys)
+			assert.NoError(t, err)
+			assert.Equal(t, kustomization, string(content))
+		})
+	}
+}
+
+func TestDerivePatchBuildFlags(t *testing.T) {
+	fSys := writeKustomization(t)
+	desired := strings.Replace(buildOutput(t, fSys), "replicas: 1", "replicas: 2", 1)
+	assert.NoError(t, fSys.WriteFile("desired.yaml", []byte(desired)))
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(kustomization+`patches:
+- patch: '[{"op": "add", "path": "/metadata/labels/x", "value": "y"}]'
+  target:
+    name: missing
+`))
+
+	cmd := NewCmdDerivePatch(fSys)
+	for _, flag := range []string{
+		"load-restrictor", "enable-alpha-plugins", "enable-helm",
+		"without-component", "fail-if-patch-no-match"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), flag)
+	}
+	assert.Nil(t, cmd.Flags().Lookup("output"))
+
+	// the build honors the flags
+	cmd.SetArgs([]string{"--fail-if-patch-no-match", "desired.yaml"})
+	err := cmd.Execute()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "patch matches no resources")
+	}
+
+	cmd = NewCmdDerivePatch(fSys)
+	cmd.SetArgs([]string{"desired.yaml"})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, desired, buildOutput(t, fSys))
+}
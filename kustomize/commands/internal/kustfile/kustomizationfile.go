@@ -64,6 +64,7 @@ func determineFieldOrder() []string {
 		"Transformers",
 		"Inventory",
 		"Components",
+		"ParameterizedComponents",
 	}
 
 	// Add deprecated fields here.
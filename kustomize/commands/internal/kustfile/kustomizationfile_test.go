@@ -46,6 +46,7 @@ func TestFieldOrder(t *testing.T) {
 		"Transformers",
 		"Inventory",
 		"Components",
+		"ParameterizedComponents",
 	}
 	actual := determineFieldOrder()
 	if len(expected) != len(actual) {
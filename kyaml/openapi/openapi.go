@@ -367,21 +367,17 @@ func (rs *ResourceSchema) Field(field string) *ResourceSchema {
 	return &ResourceSchema{Schema: &s}
 }
 
-// PatchStrategyAndKeyList returns the patch strategy and complete merge key list
+// PatchStrategyAndKeyList returns the patch strategy and complete merge key list.
+// If the schema doesn't declare a patch strategy, the structural schema list
+// type is used instead -- see ListTypeAndKeyList.
 func (rs *ResourceSchema) PatchStrategyAndKeyList() (string, []string) {
 	ps, found := rs.Schema.Extensions[kubernetesPatchStrategyExtensionKey]
 	if !found {
-		// empty patch strategy
-		return "", []string{}
+		return rs.listStrategyAndKeyList()
 	}
 	mkList, found := rs.Schema.Extensions[kubernetesMergeKeyMapList]
 	if found {
-		// mkList is []interface, convert to []string
-		mkListStr := make([]string, len(mkList.([]interface{})))
-		for i, v := range mkList.([]interface{}) {
-			mkListStr[i] = v.(string)
-		}
-		return ps.(string), mkListStr
+		return ps.(string), toStringList(mkList)
 	}
 	mk, found := rs.Schema.Extensions[kubernetesMergeKeyExtensionKey]
 	if !found {
@@ -391,12 +387,17 @@ func (rs *ResourceSchema) PatchStrategyAndKeyList() (string, []string) {
 	return ps.(string), []string{mk.(string)}
 }
 
-// PatchStrategyAndKey returns the patch strategy and merge key extensions
+// PatchStrategyAndKey returns the patch strategy and merge key extensions.
+// If the schema doesn't declare a patch strategy, the structural schema list
+// type is used instead, and the key is the first of the list map keys.
 func (rs *ResourceSchema) PatchStrategyAndKey() (string, string) {
 	ps, found := rs.Schema.Extensions[kubernetesPatchStrategyExtensionKey]
 	if !found {
-		// empty patch strategy
-		return "", ""
+		strategy, keys := rs.listStrategyAndKeyList()
+		if len(keys) == 0 {
+			return strategy, ""
+		}
+		return strategy, keys[0]
 	}
 
 	mk, found := rs.Schema.Extensions[kubernetesMergeKeyExtensionKey]
@@ -407,6 +408,71 @@ func (rs *ResourceSchema) PatchStrategyAndKey() (string, string) {
 	return ps.(string), mk.(string)
 }
 
+// ListTypeAndKeyList returns the structural schema list type
+// (x-kubernetes-list-type) and the list map keys (x-kubernetes-list-map-keys).
+// The list type is one of "atomic", "set" or "map", or empty if not set.
+func (rs *ResourceSchema) ListTypeAndKeyList() (string, []string) {
+	lt, found := rs.Schema.Extensions[kubernetesListTypeExtensionKey]
+	if !found {
+		return "", []string{}
+	}
+	ltStr, _ := lt.(string)
+	mkList, found := rs.Schema.Extensions[kubernetesMergeKeyMapList]
+	if !found || ltStr != ListTypeMap {
+		return ltStr, []string{}
+	}
+	return ltStr, toStringList(mkList)
+}
+
+// listStrategyAndKeyList translates the structural schema list type into
+// the equivalent patch strategy and merge key list.
+// "map" lists are merged on their list map keys, "set" lists are merged as
+// primitive associative lists and "atomic" lists are replaced.
+func (rs *ResourceSchema) listStrategyAndKeyList() (string, []string) {
+	lt, keys := rs.ListTypeAndKeyList()
+	switch lt {
+	case ListTypeMap, ListTypeSet:
+		return mergeStrategy, keys
+	default:
+		return "", []string{}
+	}
+}
+
+// toStringList converts an extension value holding a list of strings
+// to a []string
+func toStringList(v interface{}) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []interface{}:
+		result := make([]string, 0, len(l))
+		for i := range l {
+			if s, ok := l[i].(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return []string{}
+	}
+}
+
+const (
+	// ListTypeAtomic is the x-kubernetes-list-type of lists which are
+	// replaced as a whole
+	ListTypeAtomic = "atomic"
+
+	// ListTypeSet is the x-kubernetes-list-type of lists of unique scalars
+	ListTypeSet = "set"
+
+	// ListTypeMap is the x-kubernetes-list-type of lists of objects which
+	// are identified by the x-kubernetes-list-map-keys fields
+	ListTypeMap = "map"
+
+	// mergeStrategy is the patch strategy used to merge associative lists
+	mergeStrategy = "merge"
+)
+
 const (
 	// kubernetesOpenAPIDefaultVersion is the latest version number of the statically compiled in
 	// OpenAPI schema for kubernetes built-in types
@@ -432,6 +498,10 @@ const (
 	// -- the extension is an array of strings
 	kubernetesMergeKeyMapList = "x-kubernetes-list-map-keys"
 
+	// kubernetesListTypeExtensionKey is the key to lookup the structural schema
+	// list type -- the extension is a string
+	kubernetesListTypeExtensionKey = "x-kubernetes-list-type"
+
 	// groupKey is the key to lookup the group from the GVK extension
 	groupKey = "group"
 	// versionKey is the key to lookup the version from the GVK extension
@@ -316,3 +316,82 @@ func TestIsNamespaceScoped_custom(t *testing.T) {
 	assert.True(t, isFound)
 	assert.True(t, isNamespaceable)
 }
+
+func TestPatchStrategyAndKeyList(t *testing.T) {
+	testCases := []struct {
+		name             string
+		schema           string
+		expectedStrategy string
+		expectedKeys     []string
+	}{
+		{
+			name:             "patch strategy and merge key",
+			schema:           `{"type":"array","x-kubernetes-patch-strategy":"merge","x-kubernetes-patch-merge-key":"name"}`,
+			expectedStrategy: "merge",
+			expectedKeys:     []string{"name"},
+		},
+		{
+			name:             "patch strategy and list map keys",
+			schema:           `{"type":"array","x-kubernetes-patch-strategy":"merge","x-kubernetes-patch-merge-key":"containerPort","x-kubernetes-list-map-keys":["containerPort","protocol"]}`,
+			expectedStrategy: "merge",
+			expectedKeys:     []string{"containerPort", "protocol"},
+		},
+		{
+			name:             "list type map",
+			schema:           `{"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}`,
+			expectedStrategy: "merge",
+			expectedKeys:     []string{"containerPort", "protocol"},
+		},
+		{
+			name:             "list type set",
+			schema:           `{"type":"array","x-kubernetes-list-type":"set"}`,
+			expectedStrategy: "merge",
+			expectedKeys:     []string{},
+		},
+		{
+			name:             "list type atomic",
+			schema:           `{"type":"array","x-kubernetes-list-type":"atomic"}`,
+			expectedStrategy: "",
+			expectedKeys:     []string{},
+		},
+		{
+			name:             "patch strategy takes precedence over list type",
+			schema:           `{"type":"array","x-kubernetes-patch-strategy":"replace","x-kubernetes-list-type":"set"}`,
+			expectedStrategy: "replace",
+			expectedKeys:     []string{},
+		},
+		{
+			name:             "no extensions",
+			schema:           `{"type":"array"}`,
+			expectedStrategy: "",
+			expectedKeys:     []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := GetSchema(tc.schema, Schema())
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			strategy, keys := s.PatchStrategyAndKeyList()
+			assert.Equal(t, tc.expectedStrategy, strategy)
+			assert.Equal(t, tc.expectedKeys, keys)
+		})
+	}
+}
+
+func TestListTypeAndKeyList(t *testing.T) {
+	s, err := GetSchema(
+		`{"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}`,
+		Schema())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	listType, keys := s.ListTypeAndKeyList()
+	assert.Equal(t, ListTypeMap, listType)
+	assert.Equal(t, []string{"containerPort", "protocol"}, keys)
+	strategy, key := s.PatchStrategyAndKey()
+	assert.Equal(t, "merge", strategy)
+	assert.Equal(t, "containerPort", key)
+}
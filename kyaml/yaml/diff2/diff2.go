@@ -0,0 +1,256 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package diff2 computes the strategic merge patch which,
+// merged with merge2, changes one RNode into another.
+package diff2
+
+import (
+	"reflect"
+
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/walk"
+)
+
+// deleteDirective is the strategic merge patch directive
+// which deletes an element of an associative list.
+const deleteDirective = "$patch"
+
+// Diff returns a minimal strategic merge patch changing current into
+// desired, or nil if they're equal.  The patch has the apiVersion, kind
+// and metadata.name of current, and leaves out all unchanged fields and
+// associative list elements.
+//
+// Not all changes can be expressed, e.g. removing an element from a
+// list of primitives or reordering an associative list, so merging the
+// patch into current may not give desired.  Callers should check.
+func Diff(current, desired *yaml.RNode) (*yaml.RNode, error) {
+	d := &differ{
+		containers: map[*yaml.Node]bool{},
+		deletions:  map[*yaml.Node]*yaml.RNode{},
+	}
+	patch, err := walk.Walker{
+		Sources: []*yaml.RNode{nil, current, desired},
+		Visitor: d,
+	}.Walk()
+	if err != nil {
+		return nil, err
+	}
+	d.prune(patch.YNode())
+	if len(patch.Content()) == 0 {
+		return nil, nil
+	}
+	d.expandDeletions(patch.YNode())
+
+	// identify the resource the patch applies to
+	meta, err := current.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	result := yaml.NewMapRNode(nil)
+	if err := result.PipeE(yaml.SetField(
+		yaml.APIVersionField, yaml.NewScalarRNode(meta.APIVersion))); err != nil {
+		return nil, err
+	}
+	if err := result.PipeE(yaml.SetField(
+		yaml.KindField, yaml.NewScalarRNode(meta.Kind))); err != nil {
+		return nil, err
+	}
+	if err := result.PipeE(
+		yaml.LookupCreate(yaml.MappingNode, yaml.MetadataField),
+		yaml.SetField(yaml.NameField, yaml.NewScalarRNode(meta.Name))); err != nil {
+		return nil, err
+	}
+	fields, err := patch.Fields()
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		value := patch.Field(field).Value
+		switch field {
+		case yaml.APIVersionField, yaml.KindField:
+			continue
+		case yaml.MetadataField:
+			// merge the changed metadata with the name
+			metadata := result.Field(yaml.MetadataField).Value
+			metadataFields, err := value.Fields()
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range metadataFields {
+				if f == yaml.NameField {
+					continue
+				}
+				if err := metadata.PipeE(
+					yaml.SetField(f, value.Field(f).Value)); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if err := result.PipeE(yaml.SetField(field, value)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// differ is a walk.Visitor building the patch as the walk.Sources'
+// dest, from the current value at walk.OriginIndex and the desired
+// value at walk.UpdatedIndex.
+type differ struct {
+	// containers are the maps and lists created to hold the
+	// changes to fields or elements, which are pruned if empty
+	containers map[*yaml.Node]bool
+
+	// deletions are placeholders for deleted values, mapped to
+	// the current value, which become null, or delete directives
+	// for list elements
+	deletions map[*yaml.Node]*yaml.RNode
+}
+
+var _ walk.Visitor = &differ{}
+
+func (d *differ) VisitMap(nodes walk.Sources, _ *openapi.ResourceSchema) (*yaml.RNode, error) {
+	current, desired := nodes.Origin(), nodes.Updated()
+	switch {
+	case yaml.IsMissingOrNull(desired) && yaml.IsMissingOrNull(current):
+		return walk.ClearNode, nil
+	case yaml.IsMissingOrNull(desired):
+		return d.deletion(yaml.MappingNode, current), nil
+	case yaml.IsMissingOrNull(current):
+		return desired.Copy(), nil
+	default:
+		return d.container(yaml.MappingNode), nil
+	}
+}
+
+func (d *differ) VisitScalar(nodes walk.Sources, _ *openapi.ResourceSchema) (*yaml.RNode, error) {
+	current, desired := nodes.Origin(), nodes.Updated()
+	switch {
+	case yaml.IsMissingOrNull(desired) && yaml.IsMissingOrNull(current):
+		return walk.ClearNode, nil
+	case yaml.IsMissingOrNull(desired):
+		return d.deletion(yaml.ScalarNode, current), nil
+	case yaml.IsMissingOrNull(current):
+		return desired, nil
+	case current.YNode().Value == desired.YNode().Value &&
+		current.YNode().ShortTag() == desired.YNode().ShortTag():
+		return walk.ClearNode, nil
+	default:
+		return desired, nil
+	}
+}
+
+func (d *differ) VisitList(nodes walk.Sources, _ *openapi.ResourceSchema, kind walk.ListKind) (*yaml.RNode, error) {
+	current, desired := nodes.Origin(), nodes.Updated()
+	switch {
+	case yaml.IsMissingOrNull(desired) && yaml.IsMissingOrNull(current):
+		return walk.ClearNode, nil
+	case yaml.IsMissingOrNull(desired):
+		return d.deletion(yaml.SequenceNode, current), nil
+	case yaml.IsMissingOrNull(current):
+		return desired.Copy(), nil
+	case kind == walk.NonAssociateList:
+		// lists without merge keys are replaced as a whole
+		if equal(current, desired) {
+			return walk.ClearNode, nil
+		}
+		return desired, nil
+	default:
+		return d.container(yaml.SequenceNode), nil
+	}
+}
+
+// container returns a new node to hold the changed fields or elements.
+func (d *differ) container(kind yaml.Kind) *yaml.RNode {
+	n := &yaml.Node{Kind: kind}
+	d.containers[n] = true
+	return yaml.NewRNode(n)
+}
+
+// deletion returns a placeholder for deleting the current value.
+// The walk fills it like a container, but its content is discarded.
+func (d *differ) deletion(kind yaml.Kind, current *yaml.RNode) *yaml.RNode {
+	n := &yaml.Node{Kind: kind}
+	if kind == yaml.ScalarNode {
+		// keep the value, which may identify a list element
+		n.Value = current.YNode().Value
+	}
+	d.deletions[n] = current
+	return yaml.NewRNode(n)
+}
+
+// prune removes the containers without changes from the node,
+// and returns true if the node is such a container itself.
+func (d *differ) prune(n *yaml.Node) bool {
+	if d.deletions[n] != nil {
+		return false
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		var content []*yaml.Node
+		for i := 0; i < len(n.Content); i += 2 {
+			if !d.prune(n.Content[i+1]) {
+				content = append(content, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		var content []*yaml.Node
+		for _, e := range n.Content {
+			if !d.prune(e) {
+				content = append(content, e)
+			}
+		}
+		n.Content = content
+	}
+	return d.containers[n] && len(n.Content) == 0
+}
+
+// expandDeletions replaces the placeholders in the node with nulls,
+// or, for list elements, with directives deleting the element.
+func (d *differ) expandDeletions(n *yaml.Node) {
+	for i, c := range n.Content {
+		current, isDeletion := d.deletions[c]
+		switch {
+		case !isDeletion:
+			d.expandDeletions(c)
+		case n.Kind == yaml.SequenceNode && current.YNode().Kind == yaml.MappingNode:
+			n.Content[i] = deleteElement(current)
+		default:
+			*c = yaml.Node{Kind: yaml.ScalarNode, Tag: yaml.NodeTagNull, Value: "null"}
+		}
+	}
+}
+
+// deleteElement returns a directive deleting the element from an
+// associative list, which identifies it by its scalar fields, and so
+// by its merge keys.
+func deleteElement(element *yaml.RNode) *yaml.Node {
+	result := &yaml.Node{Kind: yaml.MappingNode}
+	content := element.YNode().Content
+	for i := 0; i < len(content); i += 2 {
+		if content[i+1].Kind == yaml.ScalarNode {
+			result.Content = append(result.Content, content[i], content[i+1])
+		}
+	}
+	result.Content = append(result.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: deleteDirective},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "delete"})
+	return result
+}
+
+// equal returns true if the nodes have the same value,
+// regardless of style and comments.
+func equal(a, b *yaml.RNode) bool {
+	var av, bv interface{}
+	if err := a.YNode().Decode(&av); err != nil {
+		return false
+	}
+	if err := b.YNode().Decode(&bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
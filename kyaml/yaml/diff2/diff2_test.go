@@ -0,0 +1,195 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff2_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	. "sigs.k8s.io/kustomize/kyaml/yaml/diff2"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+)
+
+const current = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      nodeSelector:
+        disk: ssd
+      containers:
+      - name: nginx
+        image: nginx:1.19
+        args:
+        - --port=80
+      - name: sidecar
+        image: sidecar:1.0
+`
+
+func TestDiff(t *testing.T) {
+	testCases := map[string]struct {
+		desired string
+		// expected is the patch, or empty if there are no changes
+		expected string
+		// unexpressible is true if the patch doesn't give desired
+		unexpressible bool
+	}{
+		"unchanged": {
+			desired: current,
+		},
+		"changed scalar": {
+			desired: strings.Replace(current, "replicas: 1", "replicas: 3", 1),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`,
+		},
+		"added label": {
+			desired: strings.Replace(current, "app: web", "app: web\n    tier: frontend", 1),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: frontend
+`,
+		},
+		"removed map": {
+			desired: strings.Replace(current, "      nodeSelector:\n        disk: ssd\n", "", 1),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      nodeSelector: null
+`,
+		},
+		"changed element": {
+			desired: strings.Replace(current, "nginx:1.19", "nginx:1.21", 1),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx:1.21
+        name: nginx
+`,
+		},
+		"replaced non associative list": {
+			desired: strings.Replace(current, "--port=80", "--port=8080", 1),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - args:
+        - --port=8080
+        name: nginx
+`,
+		},
+		"added element": {
+			desired: current + `      - name: proxy
+        image: envoy
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: proxy
+        image: envoy
+`,
+		},
+		"deleted element": {
+			desired: strings.Replace(current, "      - name: sidecar\n        image: sidecar:1.0\n", "", 1),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: sidecar:1.0
+        $patch: delete
+`,
+		},
+		"reordered elements": {
+			desired: strings.Replace(current, `      - name: nginx
+        image: nginx:1.19
+        args:
+        - --port=80
+      - name: sidecar
+        image: sidecar:1.0
+`, `      - name: sidecar
+        image: sidecar:1.0
+      - name: nginx
+        image: nginx:1.19
+        args:
+        - --port=80
+`, 1),
+			unexpressible: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			desired := yaml.MustParse(tc.desired)
+			patch, err := Diff(yaml.MustParse(current), desired)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if tc.expected == "" && !tc.unexpressible {
+				assert.Nil(t, patch)
+				return
+			}
+			if tc.expected != "" {
+				assert.Equal(t,
+					strings.TrimSpace(tc.expected), strings.TrimSpace(patch.MustString()))
+			}
+			merged, err := merge2.Merge(patch, yaml.MustParse(current), yaml.MergeOptions{})
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			actual, err := merged.Map()
+			assert.NoError(t, err)
+			expected, err := desired.Map()
+			assert.NoError(t, err)
+			if tc.unexpressible {
+				assert.NotEqual(t, expected, actual)
+			} else {
+				assert.Equal(t, expected, actual)
+			}
+		})
+	}
+}
@@ -487,4 +487,193 @@ items: []
 			ListIncreaseDirection: yaml.MergeOptionsListAppend,
 		},
 	},
+	//
+	// Test Case
+	//
+	{description: `merge list type map with composite keys`,
+		source: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports:
+  - containerPort: 80
+    protocol: UDP
+    name: dns-udp
+  - containerPort: 443
+    protocol: TCP
+    name: https
+`,
+		dest: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports: # {"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}
+  - containerPort: 80
+    protocol: TCP
+    name: http
+  - containerPort: 80
+    protocol: UDP
+    name: dns
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports: # {"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}
+  - containerPort: 80
+    protocol: TCP
+    name: http
+  - containerPort: 80
+    protocol: UDP
+    name: dns-udp
+  - containerPort: 443
+    protocol: TCP
+    name: https
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `merge list type map with single key`,
+		source: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  backends: # {"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["host"]}
+  - host: b.example.com
+    weight: 20
+  - host: c.example.com
+    weight: 5
+`,
+		dest: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  backends:
+  - host: a.example.com
+    weight: 10
+  - host: b.example.com
+    weight: 10
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  backends:
+  - host: a.example.com
+    weight: 10
+  - host: b.example.com
+    weight: 20
+  - host: c.example.com
+    weight: 5
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `merge list type map delete element`,
+		source: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports:
+  - containerPort: 80
+    protocol: UDP
+    $patch: delete
+`,
+		dest: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports: # {"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}
+  - containerPort: 80
+    protocol: TCP
+  - containerPort: 80
+    protocol: UDP
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports: # {"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}
+  - containerPort: 80
+    protocol: TCP
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `merge list type set`,
+		source: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  hosts:
+  - b.example.com
+  - c.example.com
+`,
+		dest: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  hosts: # {"type":"array","x-kubernetes-list-type":"set"}
+  - a.example.com
+  - b.example.com
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  hosts: # {"type":"array","x-kubernetes-list-type":"set"}
+  - a.example.com
+  - b.example.com
+  - c.example.com
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `replace list type atomic`,
+		source: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  args:
+  - --b
+`,
+		dest: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  args: # {"type":"array","x-kubernetes-list-type":"atomic"}
+  - --a
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  args: # {"type":"array","x-kubernetes-list-type":"atomic"}
+  - --b
+`,
+		infer: true,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
 }
@@ -336,4 +336,126 @@ list: # old value
 - c`,
 		expected: `
 apiVersion: apps/v1`},
+	//
+	// Test Case
+	//
+	{
+		description: `Merge list type map with composite keys`,
+		origin: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports:
+  - containerPort: 80
+    protocol: TCP
+    name: http
+  - containerPort: 53
+    protocol: UDP
+    name: dns`,
+		update: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports:
+  - containerPort: 80
+    protocol: TCP
+    name: web
+  - containerPort: 443
+    protocol: TCP
+    name: https`,
+		local: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports: # {"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}
+  - containerPort: 80
+    protocol: TCP
+    name: http
+  - containerPort: 80
+    protocol: UDP
+    name: local
+  - containerPort: 53
+    protocol: UDP
+    name: dns`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  ports: # {"type":"array","x-kubernetes-list-type":"map","x-kubernetes-list-map-keys":["containerPort","protocol"]}
+  - containerPort: 80
+    protocol: TCP
+    name: web
+  - containerPort: 80
+    protocol: UDP
+    name: local
+  - containerPort: 443
+    name: https
+    protocol: TCP`},
+
+	//
+	// Test Case
+	//
+	{
+		description: `Merge list type set`,
+		origin: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  hosts:
+  - a.example.com
+  - b.example.com`,
+		update: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  hosts:
+  - a.example.com
+  - c.example.com`,
+		local: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  hosts: # {"type":"array","x-kubernetes-list-type":"set"}
+  - a.example.com
+  - b.example.com
+  - local.example.com`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  hosts: # {"type":"array","x-kubernetes-list-type":"set"}
+  - a.example.com
+  - local.example.com
+  - c.example.com`},
+
+	//
+	// Test Case
+	//
+	{
+		description: `Replace list type atomic`,
+		origin: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  args:
+  - --a`,
+		update: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  args:
+  - --b`,
+		local: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  args: # {"type":"array","x-kubernetes-list-type":"atomic"}
+  - --a
+  - --local`,
+		expected: `
+apiVersion: example.com/v1
+kind: Foo
+spec:
+  args: # {"type":"array","x-kubernetes-list-type":"atomic"}
+  - --b`},
 }
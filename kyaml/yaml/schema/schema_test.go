@@ -46,3 +46,34 @@ func TestIsAssociativeMultipleStrategy(t *testing.T) {
 			&openapi.ResourceSchema{Schema: s},
 			[]*yaml.RNode{}, false))
 }
+
+func TestIsAssociativeListTypeMap(t *testing.T) {
+	s := makeSchema()
+	s.Extensions["x-kubernetes-list-type"] = "map"
+	s.Extensions["x-kubernetes-list-map-keys"] = []interface{}{"containerPort", "protocol"}
+	assert.True(
+		t,
+		IsAssociative(
+			&openapi.ResourceSchema{Schema: s},
+			[]*yaml.RNode{}, false))
+}
+
+func TestIsAssociativeListTypeSet(t *testing.T) {
+	s := makeSchema()
+	s.Extensions["x-kubernetes-list-type"] = "set"
+	assert.True(
+		t,
+		IsAssociative(
+			&openapi.ResourceSchema{Schema: s},
+			[]*yaml.RNode{}, false))
+}
+
+func TestIsAssociativeListTypeAtomic(t *testing.T) {
+	s := makeSchema()
+	s.Extensions["x-kubernetes-list-type"] = "atomic"
+	assert.False(
+		t,
+		IsAssociative(
+			&openapi.ResourceSchema{Schema: s},
+			[]*yaml.RNode{}, false))
+}
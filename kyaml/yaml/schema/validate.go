@@ -0,0 +1,237 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// preserveUnknownFieldsExtensionKey marks an object schema whose
+	// fields aren't pruned or validated
+	preserveUnknownFieldsExtensionKey = "x-kubernetes-preserve-unknown-fields"
+
+	// embeddedResourceExtensionKey marks an object schema holding a
+	// complete resource, which implicitly allows apiVersion, kind
+	// and metadata
+	embeddedResourceExtensionKey = "x-kubernetes-embedded-resource"
+
+	// intOrStringExtensionKey marks a schema accepting both integers
+	// and strings, as used by structural schemas
+	intOrStringExtensionKey = "x-kubernetes-int-or-string"
+
+	// intOrStringFormat is the format of the builtin IntOrString type
+	intOrStringFormat = "int-or-string"
+)
+
+// ValidationError describes a field which doesn't conform to its schema.
+type ValidationError struct {
+	// Path is the path to the offending field, e.g.
+	// [spec template spec containers[name=nginx] image]
+	Path []string
+
+	// Message describes the violation.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.FieldPath(), e.Message)
+}
+
+// FieldPath returns the path as a string, e.g.
+// spec.template.spec.containers[name=nginx].image
+func (e ValidationError) FieldPath() string {
+	var b strings.Builder
+	for i, p := range e.Path {
+		if i > 0 && !strings.HasPrefix(p, "[") {
+			b.WriteString(".")
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// Validate checks the node against the schema, and returns an error
+// for each unknown field, type mismatch and missing required field.
+//
+// Null values are always accepted.  Numbers are accepted for string
+// fields, since quantities are typed as strings but commonly written
+// as numbers.  Fields of objects which don't declare any properties,
+// or which set x-kubernetes-preserve-unknown-fields, aren't checked.
+func Validate(node *yaml.RNode, s *openapi.ResourceSchema) []ValidationError {
+	v := validator{}
+	v.validate(node, s, nil, true)
+	return v.errs
+}
+
+type validator struct {
+	errs []ValidationError
+}
+
+func (v *validator) errorf(path []string, format string, args ...interface{}) {
+	p := make([]string, len(path))
+	copy(p, path)
+	v.errs = append(v.errs, ValidationError{Path: p, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks node against s.  isResource is true if the node is
+// a complete resource, whose apiVersion, kind and metadata fields are
+// always allowed.
+func (v *validator) validate(
+	node *yaml.RNode, s *openapi.ResourceSchema, path []string, isResource bool) {
+	if s.IsMissingOrNull() || yaml.IsMissingOrNull(node) {
+		return
+	}
+	if !v.validateType(node, s, path) {
+		return
+	}
+	switch node.YNode().Kind {
+	case yaml.MappingNode:
+		v.validateMap(node, s, path, isResource)
+	case yaml.SequenceNode:
+		v.validateList(node, s, path)
+	}
+}
+
+// validateType reports a type mismatch between node and s, and returns
+// false if one was found.
+func (v *validator) validateType(node *yaml.RNode, s *openapi.ResourceSchema, path []string) bool {
+	if len(s.Schema.Type) == 0 {
+		// any type is allowed
+		return true
+	}
+	actual := nodeType(node.YNode())
+	for _, t := range s.Schema.Type {
+		if typeMatches(t, actual, s) {
+			return true
+		}
+	}
+	v.errorf(path, "expected %s, got %s",
+		strings.Join(s.Schema.Type, " or "), actual)
+	return false
+}
+
+// nodeType returns the OpenAPI type name of the node.
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch n.ShortTag() {
+	case yaml.NodeTagInt:
+		return "integer"
+	case yaml.NodeTagFloat:
+		return "number"
+	case yaml.NodeTagBool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// typeMatches returns true if a value of the actual type is
+// acceptable for the expected type.
+func typeMatches(expected, actual string, s *openapi.ResourceSchema) bool {
+	if expected == actual {
+		return true
+	}
+	switch expected {
+	case "number":
+		return actual == "integer"
+	case "string":
+		if actual == "integer" || actual == "number" {
+			// quantities are strings, but are commonly written as numbers
+			return true
+		}
+		return false
+	case "integer":
+		return actual == "string" && isIntOrString(s)
+	default:
+		return false
+	}
+}
+
+func isIntOrString(s *openapi.ResourceSchema) bool {
+	if s.Schema.Format == intOrStringFormat {
+		return true
+	}
+	v, _ := s.Schema.Extensions.GetBool(intOrStringExtensionKey)
+	return v
+}
+
+func (v *validator) validateMap(
+	node *yaml.RNode, s *openapi.ResourceSchema, path []string, isResource bool) {
+	if embedded, _ := s.Schema.Extensions.GetBool(embeddedResourceExtensionKey); embedded {
+		isResource = true
+	}
+	preserveUnknown, _ := s.Schema.Extensions.GetBool(preserveUnknownFieldsExtensionKey)
+	additional := s.Schema.AdditionalProperties
+	// additionalProperties: true allows any field without a schema
+	freeForm := (len(s.Schema.Properties) == 0 && additional == nil) ||
+		(additional != nil && additional.Allows && additional.Schema == nil)
+
+	fields, _ := node.Fields()
+	present := map[string]bool{}
+	for _, field := range fields {
+		present[field] = true
+		fieldPath := append(path, field)
+		fs := s.Field(field)
+		if fs == nil {
+			if preserveUnknown || freeForm ||
+				(isResource && isResourceMetaField(field)) {
+				continue
+			}
+			v.errorf(fieldPath, "unknown field")
+			continue
+		}
+		v.validate(node.Field(field).Value, fs, fieldPath, false)
+	}
+
+	required := append([]string{}, s.Schema.Required...)
+	sort.Strings(required)
+	for _, field := range required {
+		if !present[field] {
+			v.errorf(append(path, field), "missing required field")
+		}
+	}
+}
+
+func isResourceMetaField(field string) bool {
+	return field == yaml.APIVersionField ||
+		field == yaml.KindField ||
+		field == yaml.MetadataField
+}
+
+func (v *validator) validateList(node *yaml.RNode, s *openapi.ResourceSchema, path []string) {
+	if s.Schema.Items == nil || s.Schema.Items.Schema == nil {
+		return
+	}
+	es := s.Elements()
+	if es == nil {
+		return
+	}
+	_, keys := s.PatchStrategyAndKeyList()
+	elements, _ := node.Elements()
+	for i := range elements {
+		v.validate(elements[i], es, append(path, elementPath(elements[i], keys, i)), false)
+	}
+}
+
+// elementPath returns the path segment for a list element, using its
+// merge key value if it has one, and its index otherwise.
+func elementPath(element *yaml.RNode, keys []string, index int) string {
+	if len(keys) > 0 && element.YNode().Kind == yaml.MappingNode {
+		if f := element.Field(keys[0]); f != nil && f.Value.YNode().Kind == yaml.ScalarNode {
+			return fmt.Sprintf("[%s=%s]", keys[0], f.Value.YNode().Value)
+		}
+	}
+	return fmt.Sprintf("[%d]", index)
+}
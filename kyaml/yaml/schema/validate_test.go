@@ -0,0 +1,232 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	. "sigs.k8s.io/kustomize/kyaml/yaml/schema"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		schema   string
+		expected []string
+	}{
+		"valid deployment": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  labels:
+    app: nginx
+  creationTimestamp: null
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: nginx
+  strategy:
+    rollingUpdate:
+      maxSurge: 25%
+      maxUnavailable: 1
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+        ports:
+        - containerPort: 80
+        resources:
+          limits:
+            cpu: 1
+            memory: 512Mi
+`,
+		},
+		"type mismatches": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: three
+  paused: 1
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    spec:
+      containers: nginx
+`,
+			expected: []string{
+				"spec.replicas: expected integer, got string",
+				"spec.paused: expected boolean, got integer",
+				"spec.template.spec.containers: expected array, got string",
+			},
+		},
+		"unknown fields": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  lables:
+    app: nginx
+spec:
+  replica: 3
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    spec:
+      containers:
+      - name: nginx
+        imagePullPolcy: Always
+`,
+			expected: []string{
+				"metadata.lables: unknown field",
+				"spec.replica: unknown field",
+				"spec.template.spec.containers[name=nginx].imagePullPolcy: unknown field",
+			},
+		},
+		"missing required fields": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx
+`,
+			expected: []string{
+				"spec.template.spec.containers[0].name: missing required field",
+				"spec.selector: missing required field",
+			},
+		},
+		"custom schema": {
+			input: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  size: large
+  ports:
+  - port: 80
+    protocol: TCP
+  - port: http
+  extra:
+    anything: goes
+`,
+			schema: `{
+  "type": "object",
+  "properties": {
+    "spec": {
+      "type": "object",
+      "required": ["size"],
+      "properties": {
+        "size": {"type": "integer"},
+        "ports": {
+          "type": "array",
+          "x-kubernetes-list-type": "map",
+          "x-kubernetes-list-map-keys": ["port"],
+          "items": {
+            "type": "object",
+            "properties": {
+              "port": {"x-kubernetes-int-or-string": true},
+              "protocol": {"type": "string"}
+            }
+          }
+        },
+        "extra": {"type": "object", "x-kubernetes-preserve-unknown-fields": true}
+      }
+    }
+  }
+}`,
+			expected: []string{
+				"spec.size: expected integer, got string",
+			},
+		},
+		"additional properties allowed": {
+			input: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  size: 3
+  color: blue
+  settings:
+    anything: goes
+  strict:
+    known: yes
+    unknown: no
+`,
+			schema: `{
+  "type": "object",
+  "properties": {
+    "spec": {
+      "type": "object",
+      "properties": {
+        "size": {"type": "integer"},
+        "settings": {"type": "object", "additionalProperties": true},
+        "strict": {
+          "type": "object",
+          "properties": {"known": {"type": "string"}},
+          "additionalProperties": false
+        }
+      },
+      "additionalProperties": true
+    }
+  }
+}`,
+			expected: []string{
+				"spec.strict.unknown: unknown field",
+			},
+		},
+	}
+
+	for name := range testCases {
+		tc := testCases[name]
+		t.Run(name, func(t *testing.T) {
+			node, err := yaml.Parse(tc.input)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var s *openapi.ResourceSchema
+			if tc.schema == "" {
+				meta, err := node.GetMeta()
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				s = openapi.SchemaForResourceType(meta.TypeMeta)
+			} else {
+				s, err = openapi.GetSchema(tc.schema, openapi.Schema())
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			if !assert.NotNil(t, s) {
+				t.FailNow()
+			}
+			var actual []string
+			for _, e := range Validate(node, s) {
+				actual = append(actual, e.Error())
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -257,7 +257,8 @@ func (l *Walker) walkAssociativeSequence() (*yaml.RNode, error) {
 		return nil, err
 	}
 
-	// get the merge key(s) from schema
+	// get the merge key(s) from schema -- either the patch merge key(s) or
+	// the structural schema list map keys
 	var strategy string
 	var keys []string
 	if l.Schema != nil {
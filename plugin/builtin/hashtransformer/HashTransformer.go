@@ -9,6 +9,7 @@ import (
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 type plugin struct {
@@ -24,17 +25,31 @@ func (p *plugin) Config(
 	return nil
 }
 
-// Transform appends hash to generated resources.
+// Transform appends hash to generated resources,
+// and to those annotated for a content hash suffix.
 func (p *plugin) Transform(m resmap.ResMap) error {
 	for _, res := range m.Resources() {
-		if res.NeedHashSuffix() {
-			h, err := res.Hash(p.hasher)
-			if err != nil {
+		content := res
+		if !res.NeedHashSuffix() {
+			annotations := res.GetAnnotations()
+			mode, ok := annotations[types.ContentHashAnnotation]
+			if !ok || types.ContentHashMode(mode) != types.ContentHashSuffix {
+				continue
+			}
+			delete(annotations, types.ContentHashAnnotation)
+			if err := res.SetAnnotations(annotations); err != nil {
 				return err
 			}
-			res.StorePreviousId()
-			res.SetName(fmt.Sprintf("%s-%s", res.GetName(), h))
+			// Hash the content alone, not the build's bookkeeping.
+			content = res.DeepCopy()
+			content.RemoveBuildAnnotations()
+		}
+		h, err := content.Hash(p.hasher)
+		if err != nil {
+			return err
 		}
+		res.StorePreviousId()
+		res.SetName(fmt.Sprintf("%s-%s", res.GetName(), h))
 	}
 	return nil
 }
@@ -80,3 +80,62 @@ spec:
         name: ngnix
 `)
 }
+
+func TestHashTransformerContentHashAnnotation(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HashTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: HashTransformer
+metadata:
+  name: hasher
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+  annotations:
+    kustomize.config.k8s.io/content-hash: suffix
+data:
+  a: b
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  annotations:
+    kustomize.config.k8s.io/content-hash: suffix
+spec:
+  size: 10Gi
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: checksummed
+  annotations:
+    kustomize.config.k8s.io/content-hash: checksum
+`)
+
+	th.AssertActualEqualsExpectedNoIdAnnotations(rm, `
+apiVersion: v1
+data:
+  a: b
+kind: ConfigMap
+metadata:
+  name: cm1-4h2mbtbbt6
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db-ck8m8g85ct
+spec:
+  size: 10Gi
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: checksummed
+`)
+}
@@ -427,6 +427,45 @@ spec:
         name: tmp
 `
 
+func TestStrategicMergeTransformerOptional(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PatchStrategicMergeTransformer")
+	defer th.Reset()
+	th.WriteF("patch.yaml", `
+apiVersion: apps/v1
+metadata:
+  name: otherDeploy
+kind: Deployment
+spec:
+  replica: 3
+`)
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: PatchStrategicMergeTransformer
+metadata:
+  name: notImportantHere
+paths:
+- patch.yaml
+options:
+  optional: true
+`, target, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeploy
+spec:
+  replica: 2
+  template:
+    metadata:
+      labels:
+        old-label: old-value
+    spec:
+      containers:
+      - image: nginx
+        name: nginx
+`)
+}
+
 func TestPatchStrategicMergeTransformerCleanupItems(t *testing.T) {
 	th := kusttest_test.MakeEnhancedHarness(t).
 		PrepBuiltin("PatchStrategicMergeTransformer")
@@ -828,3 +828,51 @@ spec:
           protocol: TCP
 `)
 }
+
+func TestPatchTransformerMerge(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PatchTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+type: merge
+patch: |-
+  spec:
+    template:
+      spec:
+        containers:
+        - image: test-image:v2
+          name: test-deployment
+        terminationGracePeriodSeconds: null
+target:
+  kind: Deployment
+`, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - image: test-image
+        name: test-deployment
+      - image: sidecar
+        name: sidecar
+      terminationGracePeriodSeconds: 30
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - image: test-image:v2
+        name: test-deployment
+`)
+}
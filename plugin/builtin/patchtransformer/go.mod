@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/evanphx/json-patch v4.5.0+incompatible
 	sigs.k8s.io/kustomize/api v0.8.9
+	sigs.k8s.io/kustomize/kyaml v0.10.20
 	sigs.k8s.io/yaml v1.2.0
 )
 
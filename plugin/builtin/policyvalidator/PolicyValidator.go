@@ -0,0 +1,87 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/policyvalidator"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Check the resources against declarative policy rules,
+// reporting every violation at once.
+// The resources are never modified.
+type plugin struct {
+	Rules []types.PolicyRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Rules = nil
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("must specify at least one rule")
+	}
+	for i, r := range p.Rules {
+		for _, f := range r.ForbiddenFields {
+			if f.Path == "" {
+				return fmt.Errorf(
+					"forbidden field of rule %s must have a path",
+					p.ruleName(i))
+			}
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	var violations []string
+	for i, r := range p.Rules {
+		resources := m.Resources()
+		if r.Target != nil {
+			var err error
+			resources, err = m.Select(*r.Target)
+			if err != nil {
+				return err
+			}
+		}
+		for _, res := range resources {
+			err := res.ApplyFilter(policyvalidator.Filter{Rule: r})
+			var ve *policyvalidator.ViolationError
+			if errors.As(err, &ve) {
+				for _, v := range ve.Violations {
+					violations = append(violations, fmt.Sprintf(
+						"rule %s: %s: %s", p.ruleName(i), res.CurId(), v))
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d policy violation(s):\n  %s",
+			len(violations), strings.Join(violations, "\n  "))
+	}
+	return nil
+}
+
+// ruleName identifies the i'th rule by name, or by index if unnamed.
+func (p *plugin) ruleName(i int) string {
+	if p.Rules[i].Name != "" {
+		return p.Rules[i].Name
+	}
+	return fmt.Sprintf("#%d", i)
+}
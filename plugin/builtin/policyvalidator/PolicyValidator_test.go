@@ -0,0 +1,147 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const policyInput = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+      - name: nginx
+        image: nginx:1.19
+        resources:
+          limits:
+            cpu: 100m
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`
+
+func TestPolicyValidatorSatisfied(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PolicyValidator")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: PolicyValidator
+metadata:
+  name: notImportantHere
+rules:
+- name: workloads
+  target:
+    kind: Deployment
+  requiredLabels:
+  - app
+  allowedImageRegistries:
+  - docker.io
+  requiredResourceLimits:
+  - cpu
+`, policyInput, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: web
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx:1.19
+        name: nginx
+        resources:
+          limits:
+            cpu: 100m
+      hostNetwork: true
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+}
+
+func TestPolicyValidatorViolations(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PolicyValidator")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: PolicyValidator
+metadata:
+  name: notImportantHere
+rules:
+- requiredLabels:
+  - app
+- name: workloads
+  target:
+    kind: Deployment
+  forbiddenFields:
+  - path: spec/template/spec/hostNetwork
+    value: "true"
+  allowedImageRegistries:
+  - gcr.io/my-project
+  requiredResourceLimits:
+  - memory
+`, policyInput)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `4 policy violation(s):
+  rule #0: ~G_v1_ConfigMap|~X|cm: missing required label "app"
+  rule workloads: apps_v1_Deployment|~X|web: forbidden field spec/template/spec/hostNetwork is set to true
+  rule workloads: apps_v1_Deployment|~X|web: container "nginx" image "nginx:1.19" is not from an allowed registry [gcr.io/my-project]
+  rule workloads: apps_v1_Deployment|~X|web: container "nginx" has no memory limit`, err.Error())
+}
+
+func TestPolicyValidatorConfigErrors(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PolicyValidator")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: PolicyValidator
+metadata:
+  name: notImportantHere
+`, policyInput)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), "must specify at least one rule")
+
+	err = th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: PolicyValidator
+metadata:
+  name: notImportantHere
+rules:
+- name: noPath
+  forbiddenFields:
+  - value: "true"
+`, policyInput)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(),
+		"forbidden field of rule noPath must have a path")
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Remove the resources matching any of the targets,
+// e.g. to drop the Secrets of a base.  The group, version,
+// kind, name and namespace of a target are regular expressions
+// matching the whole field: kind Role doesn't match RoleBinding,
+// while Role.* matches both.
+type plugin struct {
+	Targets []types.Selector `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Targets = nil
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("must specify at least one target")
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, t := range p.Targets {
+		resources, err := m.Select(t)
+		if err != nil {
+			return err
+		}
+		for _, r := range resources {
+			if err = m.Remove(r.CurId()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
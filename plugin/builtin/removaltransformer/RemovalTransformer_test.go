@@ -0,0 +1,78 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const removalInput = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels:
+    debug: "true"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: reader
+`
+
+func TestRemovalTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RemovalTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: RemovalTransformer
+metadata:
+  name: notImportantHere
+targets:
+- kind: Secret
+- kind: ConfigMap
+  labelSelector: debug=true
+`, removalInput, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: reader
+`)
+}
+
+func TestRemovalTransformerNoTargets(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RemovalTransformer")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: RemovalTransformer
+metadata:
+  name: notImportantHere
+`, removalInput)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must specify at least one target")
+	}
+}
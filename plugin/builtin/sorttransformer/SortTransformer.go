@@ -0,0 +1,94 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Sort the resources by an ordered list of Gvk patterns,
+// e.g. CRDs and Namespaces first and webhooks last,
+// then by the configured tie-breakers.
+// The sort is stable, so remaining ties keep their order.
+type plugin struct {
+	types.SortOptions `json:",inline,omitempty" yaml:",inline,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.SortOptions = types.SortOptions{}
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	for _, t := range p.TieBreakers {
+		if t != types.SortByNamespace && t != types.SortByName {
+			return fmt.Errorf(
+				"unknown tie-breaker '%s'; must be %s or %s",
+				t, types.SortByNamespace, types.SortByName)
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	resources := m.Resources()
+	sort.SliceStable(resources, func(i, j int) bool {
+		return p.less(resources[i], resources[j])
+	})
+	m.Clear()
+	for _, r := range resources {
+		if err := m.Append(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) less(a, b *resource.Resource) bool {
+	if ra, rb := p.rank(a), p.rank(b); ra != rb {
+		return ra < rb
+	}
+	for _, t := range p.TieBreakers {
+		var va, vb string
+		switch t {
+		case types.SortByNamespace:
+			va, vb = a.GetNamespace(), b.GetNamespace()
+		case types.SortByName:
+			va, vb = a.GetName(), b.GetName()
+		}
+		if va != vb {
+			return va < vb
+		}
+	}
+	return false
+}
+
+// rank is the position of the resource's group: that of the
+// first pattern it matches in First, then that of resources
+// matching no pattern, then that of the patterns in Last.
+func (p *plugin) rank(r *resource.Resource) int {
+	gvk := r.GetGvk()
+	for i := range p.First {
+		if gvk.IsSelected(&p.First[i]) {
+			return i
+		}
+	}
+	for i := range p.Last {
+		if gvk.IsSelected(&p.Last[i]) {
+			return len(p.First) + 1 + i
+		}
+	}
+	return len(p.First)
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const sortInput = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: hook
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  namespace: b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: a
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  namespace: a
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+`
+
+func TestSortTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("SortTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: SortTransformer
+metadata:
+  name: notImportantHere
+first:
+- kind: CustomResourceDefinition
+- kind: Namespace
+- group: example.com
+last:
+- kind: ValidatingWebhookConfiguration
+tieBreakers:
+- namespace
+- name
+`, sortInput, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  namespace: a
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  namespace: b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: b
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: hook
+`)
+}
+
+func TestSortTransformerKeepsInputOrderOfTies(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("SortTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: SortTransformer
+metadata:
+  name: notImportantHere
+last:
+- group: example.com
+`, sortInput, `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: hook
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: a
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: a
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  namespace: b
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  namespace: a
+`)
+}
+
+func TestSortTransformerUnknownTieBreaker(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("SortTransformer")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: SortTransformer
+metadata:
+  name: notImportantHere
+tieBreakers:
+- kind
+`, sortInput)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"unknown tie-breaker 'kind'; must be namespace or name")
+	}
+}